@@ -0,0 +1,295 @@
+package transducers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Numeric values, as the aggregate reducers see them: ints (and uints that
+// fit) are kept exact, everything else is a float64.
+type number struct {
+	i     int64
+	f     float64
+	float bool
+}
+
+func (n number) float64() float64 {
+	if n.float {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+func (n number) less(o number) bool {
+	if n.float || o.float {
+		return n.float64() < o.float64()
+	}
+	return n.i < o.i
+}
+
+func numberOf(value interface{}) (number, error) {
+	// common cases first, to skip reflection
+	switch v := value.(type) {
+	case int:
+		return number{i: int64(v)}, nil
+	case int64:
+		return number{i: v}, nil
+	case float64:
+		return number{f: v, float: true}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number{i: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return number{i: int64(u)}, nil
+		}
+		return number{f: float64(rv.Uint()), float: true}, nil
+	case reflect.Float32, reflect.Float64:
+		return number{f: rv.Float(), float: true}, nil
+	}
+
+	return number{}, fmt.Errorf("cannot aggregate %T: %w", value, ErrNotSupported)
+}
+
+// Calls f with each value - or, if it's a ValueStream, each value in it,
+// flattened - and its number. If any of them isn't numeric, f isn't called at
+// all, and an error wrapping ErrNotSupported is returned.
+func eachNumber(value interface{}, f func(interface{}, number)) error {
+	vs, ok := value.(ValueStream)
+	if !ok {
+		n, err := numberOf(value)
+		if err == nil {
+			f(value, n)
+		}
+		return err
+	}
+
+	var values []interface{}
+	var numbers []number
+	var err error
+	vs.Flatten().Each(func(v interface{}) {
+		if err != nil {
+			return
+		}
+		var n number
+		if n, err = numberOf(v); err == nil {
+			values, numbers = append(values, v), append(numbers, n)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		f(v, numbers[k])
+	}
+	return nil
+}
+
+// An aggregate bottom reducer. The accumulators are all values, not pointers,
+// so each one that's returned is independent of those before it.
+type aggregate struct {
+	init     interface{}
+	step     func(accum interface{}, value interface{}, n number) interface{}
+	complete func(accum interface{}) interface{}
+}
+
+func (r aggregate) stepErr(accum interface{}, value interface{}) (interface{}, error) {
+	next := accum
+	err := eachNumber(value, func(v interface{}, n number) {
+		next = r.step(next, v, n)
+	})
+	if err != nil {
+		return accum, err
+	}
+	return next, nil
+}
+
+func (r aggregate) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	accum, err := r.stepErr(accum, value)
+	if err != nil {
+		panic(err)
+	}
+	return accum, false
+}
+
+func (r aggregate) Complete(accum interface{}) interface{} {
+	return r.complete(accum)
+}
+
+func (r aggregate) Init() interface{} {
+	return r.init
+}
+
+type aggregateErr struct {
+	aggregate
+}
+
+func (r aggregateErr) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	accum, err := r.stepErr(accum, value)
+	return accum, false, err
+}
+
+func (r aggregateErr) Complete(accum interface{}) (interface{}, error) {
+	return r.complete(accum), nil
+}
+
+// AggregateErr turns one of the numeric aggregates (Total, Min, Max, Mean,
+// Variance, SampleVariance) into an ErrReducer. Rather than panicking on a
+// non-numeric value, it drops it and returns an error wrapping
+// ErrNotSupported; a ValueStream with any non-numeric value in it is dropped
+// whole. Any other reducer is wrapped with ErrAware.
+func AggregateErr(r Reducer) ErrReducer {
+	if a, ok := r.(aggregate); ok {
+		return aggregateErr{a}
+	}
+	return ErrAware(r)
+}
+
+// Count is a bottom reducer that counts the values reaching it, as an int.
+// ValueStreams count for each of the values in them, flattened; unlike the
+// other aggregates, values don't have to be numbers.
+func Count() Reducer {
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		if vs, ok := value.(ValueStream); ok {
+			vs.Flatten().Each(func(interface{}) {
+				accum = accum.(int) + 1
+			})
+			return accum, false
+		}
+		return accum.(int) + 1, false
+	})
+	r.I = func() interface{} {
+		return 0
+	}
+
+	return r
+}
+
+// Total is a bottom reducer that adds up the values reaching it. They can be
+// of any numeric kind, and ValueStreams are flattened into their values.
+//
+// The total is an int64, or a float64 if any of the values were floats. With
+// no values, it's int64(0).
+func Total() Reducer {
+	return aggregate{
+		init: number{},
+		step: func(accum interface{}, _ interface{}, n number) interface{} {
+			t := accum.(number)
+			if t.float || n.float {
+				return number{f: t.float64() + n.float64(), float: true}
+			}
+			return number{i: t.i + n.i}
+		},
+		complete: func(accum interface{}) interface{} {
+			t := accum.(number)
+			if t.float {
+				return t.f
+			}
+			return t.i
+		},
+	}
+}
+
+// The smallest or largest value so far, as given, and its number.
+type extreme struct {
+	value interface{}
+	n     number
+	any   bool
+}
+
+func extremeOf(less func(a, b number) bool) Reducer {
+	return aggregate{
+		init: extreme{},
+		step: func(accum interface{}, value interface{}, n number) interface{} {
+			e := accum.(extreme)
+			if !e.any || less(n, e.n) {
+				return extreme{value, n, true}
+			}
+			return e
+		},
+		complete: func(accum interface{}) interface{} {
+			return accum.(extreme).value
+		},
+	}
+}
+
+// Min is a bottom reducer that finds the smallest of the values reaching it,
+// which can be of any numeric kind (ValueStreams are flattened). The value is
+// returned as it was given, or nil if there were none.
+func Min() Reducer {
+	return extremeOf(func(a, b number) bool {
+		return a.less(b)
+	})
+}
+
+// Max is Min, but finds the largest value.
+func Max() Reducer {
+	return extremeOf(func(a, b number) bool {
+		return b.less(a)
+	})
+}
+
+// Running count, mean and sum of squared differences from the mean, updated
+// with Welford's algorithm so as to stay accurate over long streams.
+type moments struct {
+	n        int
+	mean, m2 float64
+}
+
+func momentsOf(complete func(m moments) interface{}) Reducer {
+	return aggregate{
+		init: moments{},
+		step: func(accum interface{}, _ interface{}, n number) interface{} {
+			m := accum.(moments)
+			x := n.float64()
+			m.n++
+			delta := x - m.mean
+			m.mean += delta / float64(m.n)
+			m.m2 += delta * (x - m.mean)
+			return m
+		},
+		complete: func(accum interface{}) interface{} {
+			return complete(accum.(moments))
+		},
+	}
+}
+
+// Mean is a bottom reducer that finds the arithmetic mean of the values
+// reaching it, which can be of any numeric kind (ValueStreams are flattened).
+// The mean is a float64, or nil if there were no values.
+func Mean() Reducer {
+	return momentsOf(func(m moments) interface{} {
+		if m.n == 0 {
+			return nil
+		}
+		return m.mean
+	})
+}
+
+// Variance is a bottom reducer that finds the population variance of the
+// values reaching it, as Mean does the mean. It's a float64, or nil if there
+// were no values.
+func Variance() Reducer {
+	return momentsOf(func(m moments) interface{} {
+		if m.n == 0 {
+			return nil
+		}
+		return m.m2 / float64(m.n)
+	})
+}
+
+// SampleVariance is Variance, but finds the sample variance (with Bessel's
+// correction) instead. It's nil if there were fewer than two values.
+func SampleVariance() Reducer {
+	return momentsOf(func(m moments) interface{} {
+		if m.n < 2 {
+			return nil
+		}
+		return m.m2 / float64(m.n-1)
+	})
+}
@@ -0,0 +1,95 @@
+package transducers
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	if c := Transduce(Range(10), Count(), Filter(Even)); c != 5 {
+		t.Error("Expected 5, got", c)
+	}
+	if c := Transduce(toi("a", "b"), Count()); c != 2 {
+		t.Error("Expected 2, got", c)
+	}
+	if c := Transduce(Range(7), Count(), Chunk(3)); c != 7 {
+		t.Error("Chunks should count for each of their values, got", c)
+	}
+}
+
+func TestTotal(t *testing.T) {
+	if s := Transduce([]int8{1, 2, 3}, Total()); s != int64(6) {
+		t.Errorf("Expected int64(6), got %#v", s)
+	}
+	if s := Transduce(toi(1, uint16(2), 0.5), Total()); s != 3.5 {
+		t.Errorf("Expected 3.5, got %#v", s)
+	}
+	if s := Transduce(Range(5), Total(), Chunk(2)); s != int64(10) {
+		t.Errorf("Expected int64(10), got %#v", s)
+	}
+	if s := Transduce([]int{}, Total()); s != int64(0) {
+		t.Errorf("Expected int64(0), got %#v", s)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	vals := toi(3, 1.5, int8(-2), uint(7), 4)
+	if m := Transduce(vals, Min()); m != int8(-2) {
+		t.Errorf("Expected int8(-2), got %#v", m)
+	}
+	if m := Transduce(vals, Max()); m != uint(7) {
+		t.Errorf("Expected uint(7), got %#v", m)
+	}
+	if m := Transduce([]int{}, Max()); m != nil {
+		t.Errorf("Expected nil, got %#v", m)
+	}
+}
+
+func TestMeanVariance(t *testing.T) {
+	vals := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	if m := Transduce(vals, Mean()); m != 5.0 {
+		t.Errorf("Expected 5, got %#v", m)
+	}
+	if v := Transduce(vals, Variance()); v != 4.0 {
+		t.Errorf("Expected 4, got %#v", v)
+	}
+	if v := Transduce(vals, SampleVariance()); math.Abs(v.(float64)-32.0/7) > 1e-12 {
+		t.Errorf("Expected 32/7, got %#v", v)
+	}
+
+	if m := Transduce([]int{}, Mean()); m != nil {
+		t.Errorf("Expected nil, got %#v", m)
+	}
+	if v := Transduce([]int{1}, SampleVariance()); v != nil {
+		t.Errorf("Expected nil, got %#v", v)
+	}
+
+	// large offsets don't swamp the variance
+	shifted := make([]interface{}, len(vals))
+	for k, v := range vals {
+		shifted[k] = v + 1e9
+	}
+	if v := Transduce(shifted, Variance()); math.Abs(v.(float64)-4) > 1e-6 {
+		t.Errorf("Expected 4, got %#v", v)
+	}
+}
+
+func TestAggregateNotNumeric(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic on a non-numeric value")
+		}
+	}()
+	Transduce(toi("a"), Total())
+}
+
+func TestAggregateErr(t *testing.T) {
+	total, errs := TransduceErrs(toi(1, "a", 2, ToStream(toi(3, "b")), ToStream(toi(4, 5))), AggregateErr(Total()))
+	if total != int64(12) {
+		t.Errorf("Expected 12, got %#v", total)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrNotSupported) {
+		t.Error("Expected two ErrNotSupported errors, got", errs)
+	}
+}
@@ -0,0 +1,113 @@
+package transducers
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock is where time-based transducers get the time from, and how they
+// schedule work for later. Swap in a ManualClock to test them without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer is returned by Clock.AfterFunc. Stop prevents its func from being
+// called, and reports whether it did so (false if the func already ran).
+type Timer interface {
+	Stop() bool
+}
+
+// SystemClock is the Clock backed by the time package.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// A ManualClock only moves when it's told to, via Advance. Timers' funcs are
+// called by Advance itself, rather than in their own goroutines.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock creates a ManualClock, stopped at the given time.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that comes due
+// along the way, in order. While each timer's func runs, Now reports the time
+// it was due.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+
+	for {
+		next := -1
+		for k, t := range c.timers {
+			if !t.at.After(target) && (next == -1 || t.at.Before(c.timers[next].at)) {
+				next = k
+			}
+		}
+
+		if next == -1 {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+
+		// the func may well use the clock, so don't hold the lock while it runs
+		c.mu.Unlock()
+		t.f()
+		c.mu.Lock()
+	}
+}
+
+type manualTimer struct {
+	c  *ManualClock
+	at time.Time
+	f  func()
+}
+
+func (t *manualTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	for k, ot := range t.c.timers {
+		if ot == t {
+			t.c.timers = append(t.c.timers[:k], t.c.timers[k+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package transducers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A strict reducer checks the termination protocol from both sides: it fails
+// the test if it's stepped after it has returned terminate, or if it's
+// completed more than once. If stopAfter is set, it terminates the process
+// itself after that many steps.
+type strict struct {
+	t         *testing.T
+	name      string
+	next      Reducer
+	stopAfter int
+	steps     int
+	term      bool
+	completes int
+}
+
+func (r *strict) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	if r.term {
+		r.t.Errorf("%s: stepped after terminating", r.name)
+	}
+	r.steps++
+
+	accum, r.term = r.next.Step(accum, value)
+	if r.stopAfter > 0 && r.steps >= r.stopAfter {
+		r.term = true
+	}
+	return accum, r.term
+}
+
+func (r *strict) Complete(accum interface{}) interface{} {
+	r.completes++
+	if r.completes > 1 {
+		r.t.Errorf("%s: completed %v times", r.name, r.completes)
+	}
+	return r.next.Complete(accum)
+}
+
+func (r *strict) Init() interface{} {
+	return r.next.Init()
+}
+
+// Every shipped transducer, made fresh for each run.
+func conformanceSubjects() map[string]func() Transducer {
+	clock := NewManualClock(time.Unix(0, 0))
+	seconds := func(v interface{}) interface{} {
+		return time.Unix(int64(v.(int)), 0)
+	}
+	quiet := func(string, ...interface{}) (int, error) {
+		return 0, nil
+	}
+	lessThan := func(n int) Filterer {
+		return func(v interface{}) bool {
+			return v.(int) < n
+		}
+	}
+	odds := func(v interface{}) interface{} {
+		if Even(v) {
+			return nil
+		}
+		return v
+	}
+
+	return map[string]func() Transducer{
+		"Map":         func() Transducer { return Map(Inc) },
+		"Filter":      func() Transducer { return Filter(Even) },
+		"Remove":      func() Transducer { return Remove(Even) },
+		"Mapcat":      func() Transducer { return Mapcat(Range) },
+		"Dedupe":      func() Transducer { return Dedupe() },
+		"Distinct":    func() Transducer { return Distinct() },
+		"DistinctLRU": func() Transducer { return DistinctLRU(2, nil) },
+		"DistinctBloom": func() Transducer {
+			return DistinctBloom(100, 0.01, nil)
+		},
+		"Chunk": func() Transducer { return Chunk(3) },
+		"ChunkBy": func() Transducer {
+			return ChunkBy(func(v interface{}) interface{} { return v.(int) / 4 })
+		},
+		"Partition":    func() Transducer { return Partition(3, 1) },
+		"PartitionPad": func() Transducer { return PartitionPad(3, 2, []int{-1}) },
+		"PartitionAll": func() Transducer { return PartitionAll(3, 1) },
+		"RandomSample": func() Transducer { return RandomSample(1) },
+		"TakeNth":      func() Transducer { return TakeNth(2) },
+		"Keep":         func() Transducer { return Keep(odds) },
+		"KeepIndexed": func() Transducer {
+			return KeepIndexed(func(i int, v interface{}) interface{} { return odds(i) })
+		},
+		"Replace":   func() Transducer { return Replace(map[interface{}]interface{}{2: 20}) },
+		"Take":      func() Transducer { return Take(5) },
+		"Take0":     func() Transducer { return Take(0) },
+		"TakeWhile": func() Transducer { return TakeWhile(lessThan(10)) },
+		"Drop":      func() Transducer { return Drop(3) },
+		"DropWhile": func() Transducer { return DropWhile(lessThan(3)) },
+		"Escape": func() Transducer {
+			return Escape(Even, make(chan interface{}, 100), true)
+		},
+		"Scan":       func() Transducer { return Scan(0, add) },
+		"Reductions": func() Transducer { return Reductions(Total()) },
+		"Comp":       func() Transducer { return Comp(Mapcat(Range), Chunk(2)) },
+		"Xform":      func() Transducer { return Xform{}.Map(Inc).Take(4).Transducer() },
+		"Parallel":   func() Transducer { return Parallel(3, Map(Inc)) },
+		"ParallelUnordered": func() Transducer {
+			return ParallelUnordered(3, Mapcat(Range))
+		},
+		"WindowByTime": func() Transducer {
+			return Comp(Map(func(v interface{}) interface{} {
+				if v.(int)%4 == 0 {
+					clock.Advance(time.Minute)
+				}
+				return v
+			}), WindowByTime(time.Minute, clock))
+		},
+		"BatchBySizeOrTime": func() Transducer {
+			return BatchBySizeOrTime(3, time.Minute, clock)
+		},
+		"EventWindows": func() Transducer {
+			return EventWindows(SlidingWindows(3*time.Second, time.Second), seconds, BoundedOutOfOrderness(0), nil, false)
+		},
+		"Keyed": func() Transducer {
+			return Keyed(func(v interface{}) interface{} { return v.(int) % 3 }, func() Transducer {
+				return Comp(Chunk(2), Take(2))
+			})
+		},
+		"KeyedTTL": func() Transducer {
+			return KeyedWith(func(v interface{}) interface{} { return v.(int) % 3 }, func() Transducer {
+				return Chunk(2)
+			}, KeyedOptions{TTL: time.Minute, Clock: clock})
+		},
+		"Loggers": func() Transducer {
+			return Comp(AttachLoggers(quiet, Chunk(2), Mapcat(Flatten))...)
+		},
+	}
+}
+
+// Processors, each running the stack over the input to the end.
+var conformanceProcessors = map[string]func(coll interface{}, tds ...Transducer){
+	"Transduce": func(coll interface{}, tds ...Transducer) {
+		Transduce(coll, CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+			return append(accum.([]interface{}), value), false
+		}), tds...)
+	},
+	"Eduction": func(coll interface{}, tds ...Transducer) {
+		ToSlice(Eduction(coll, tds...))
+	},
+	"EductionSeq": func(coll interface{}, tds ...Transducer) {
+		for range EductionSeq(coll, tds...) {
+		}
+	},
+	"Go": func(coll interface{}, tds ...Transducer) {
+		for range Go(coll, 0, tds...) {
+		}
+	},
+	"TransduceErr": func(coll interface{}, tds ...Transducer) {
+		var etds []ErrTransducer
+		for _, td := range tds {
+			etds = append(etds, Lift(td))
+		}
+		TransduceErr(coll, AppendErr(), etds...)
+	},
+	"TransduceContext": func(coll interface{}, tds ...Transducer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		TransduceContext(ctx, coll, CreateStep(nil), tds...)
+	},
+}
+
+func TestTerminationConformance(t *testing.T) {
+	input := t_range(20)
+
+	for pname, process := range conformanceProcessors {
+		for sname, subject := range conformanceSubjects() {
+			for _, stop := range []int{1, 2, 3, 7, 100} {
+				var made []*strict
+				strictly := func(name string, stopAfter int) Transducer {
+					return func(r Reducer) Reducer {
+						s := &strict{t: t, name: pname + "/" + sname + "/" + name, next: r, stopAfter: stopAfter}
+						made = append(made, s)
+						return s
+					}
+				}
+
+				process(input, strictly("processor", 0), subject(), strictly("transducer", stop))
+
+				for _, s := range made {
+					if s.completes != 1 {
+						t.Errorf("%s: completed %v times", s.name, s.completes)
+					}
+				}
+			}
+		}
+	}
+}
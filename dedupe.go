@@ -0,0 +1,293 @@
+package transducers
+
+import (
+	"container/list"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func identity(value interface{}) interface{} {
+	return value
+}
+
+// A key standing in for a value that can't be used as a map key.
+type printedKey string
+
+// hashKey returns the value itself if it can be used as a map key. If not, it
+// falls back on an encoding of the value's structure, which, like ==, takes
+// the dynamic type of everything in it into account - so []interface{}{1} and
+// []interface{}{1.0} are different keys.
+func hashKey(value interface{}) interface{} {
+	if value == nil || reflect.ValueOf(value).Comparable() {
+		return value
+	}
+
+	var b strings.Builder
+	writeKey(&b, reflect.ValueOf(value))
+	return printedKey(b.String())
+}
+
+// Writes out the dynamic type of v, and then its contents.
+func writeKey(b *strings.Builder, v reflect.Value) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	b.WriteString(v.Type().String())
+	b.WriteByte('(')
+	writeContents(b, v)
+	b.WriteByte(')')
+}
+
+// Writes out v's contents. Its type is already known from its container's, so
+// only values in interfaces need theirs written out.
+func writeContents(b *strings.Builder, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		writeKey(b, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			writeContents(b, v.Index(i))
+			b.WriteByte(',')
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		// maps are unordered, so sort the entries to get the same key each time
+		entries := make([]string, 0, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			var e strings.Builder
+			writeContents(&e, it.Key())
+			e.WriteByte(':')
+			writeContents(&e, it.Value())
+			entries = append(entries, e.String())
+		}
+		sort.Strings(entries)
+		for _, e := range entries {
+			b.WriteString(e)
+			b.WriteByte(',')
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeContents(b, v.Field(i))
+			b.WriteByte(',')
+		}
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		b.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	default:
+		// pointers, chans and funcs, which are compared by identity
+		fmt.Fprintf(b, "%#x", v.Pointer())
+	}
+}
+
+// keyOf returns the key a value is deduped by, compared by hashKey. Streams
+// are compared by their contents, so when either the value or its key is one,
+// it gets read out. Reading a stream uses it up, so the value is split first,
+// and one half returned to pass along in place of the original.
+func keyOf(key Mapper, value interface{}) (k interface{}, pass interface{}) {
+	k, value = rawKeyOf(key, value)
+	return hashKey(k), value
+}
+
+// rawKeyOf is keyOf, but leaves the key as it is, other than reading it out if
+// it's a stream.
+func rawKeyOf(key Mapper, value interface{}) (k interface{}, pass interface{}) {
+	if vs, ok := value.(ValueStream); ok {
+		vs, dup := vs.Split()
+		value, k = vs, key(dup)
+	} else {
+		k = key(value)
+	}
+
+	if kvs, ok := k.(ValueStream); ok {
+		k = ToSlice(kvs)
+	}
+	return k, value
+}
+
+// DedupeBy is Dedupe, but compares the keys the given func returns for each
+// value, rather than the values themselves.
+func DedupeBy(key Mapper) Transducer {
+	if key == nil {
+		key = identity
+	}
+
+	return func(r Reducer) Reducer {
+		return &dedupe{reducerBase: reducerBase{r}, key: key}
+	}
+}
+
+type distinct struct {
+	reducerBase
+	key  Mapper
+	seen map[interface{}]struct{}
+}
+
+func (r *distinct) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	k, value := keyOf(r.key, value)
+	if _, has := r.seen[k]; has {
+		return accum, false
+	}
+
+	r.seen[k] = struct{}{}
+	return r.next.Step(accum, value)
+}
+
+// Distinct keeps track of values that have passed through it during this
+// transduction process and drops any repeats, like Clojure's distinct. Values
+// are compared just as they are by Dedupe.
+//
+// Every distinct value is remembered until the process completes, so on an
+// unbounded stream, look at DistinctLRU or DistinctBloom instead.
+func Distinct() Transducer {
+	return DistinctBy(identity)
+}
+
+// DistinctBy is Distinct, but compares the keys the given func returns for
+// each value, rather than the values themselves. Use it to find distinct
+// structs by an ID field, say, or slices by some cheaper summary of their
+// contents.
+func DistinctBy(key Mapper) Transducer {
+	if key == nil {
+		key = identity
+	}
+
+	return func(r Reducer) Reducer {
+		return &distinct{reducerBase{r}, key, make(map[interface{}]struct{})}
+	}
+}
+
+type distinctLRU struct {
+	reducerBase
+	key   Mapper
+	size  int
+	order *list.List // most recently seen at the front
+	seen  map[interface{}]*list.Element
+}
+
+func (r *distinctLRU) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	k, value := keyOf(r.key, value)
+	if e, has := r.seen[k]; has {
+		r.order.MoveToFront(e)
+		return accum, false
+	}
+
+	r.seen[k] = r.order.PushFront(k)
+	if r.order.Len() > r.size {
+		delete(r.seen, r.order.Remove(r.order.Back()))
+	}
+
+	return r.next.Step(accum, value)
+}
+
+// DistinctLRU is DistinctBy, but only remembers the given number of keys - the
+// ones seen most recently. Once a key is forgotten, it'll be let through again
+// the next time it shows up.
+//
+// Memory use is bounded by the size of the window, so it's suitable for
+// unbounded streams where duplicates tend to arrive close together. If key is
+// nil, values are compared directly, as with Distinct.
+func DistinctLRU(size int, key Mapper) Transducer {
+	if size < 1 {
+		panic("LRU window must hold at least one key")
+	}
+	if key == nil {
+		key = identity
+	}
+
+	return func(r Reducer) Reducer {
+		return &distinctLRU{
+			reducerBase: reducerBase{r},
+			key:         key,
+			size:        size,
+			order:       list.New(),
+			seen:        make(map[interface{}]*list.Element),
+		}
+	}
+}
+
+type distinctBloom struct {
+	reducerBase
+	key    Mapper
+	seed   maphash.Seed
+	bits   []uint64
+	m      uint64 // number of bits
+	hashes uint64 // number of bits set per key
+}
+
+func (r *distinctBloom) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	// derive all the bit positions from one hash (Kirsch & Mitzenmacher)
+	k, value := keyOf(r.key, value)
+	h := maphash.Comparable(r.seed, k)
+	h1, h2 := h&math.MaxUint32, h>>32|1
+
+	has := true
+	for i := uint64(0); i < r.hashes; i++ {
+		bit := (h1 + i*h2) % r.m
+		if r.bits[bit/64]&(1<<(bit%64)) == 0 {
+			has = false
+			r.bits[bit/64] |= 1 << (bit % 64)
+		}
+	}
+
+	if has {
+		return accum, false
+	}
+	return r.next.Step(accum, value)
+}
+
+// DistinctBloom is DistinctBy, but tracks keys in a Bloom filter rather than a
+// set, so memory use is fixed up front no matter how long the stream runs.
+//
+// The tradeoff is that it's probabilistic: it never lets a duplicate through,
+// but it will occasionally drop a value that wasn't one. The filter is sized so
+// that after capacity distinct keys, the chance of that is fpRate. Past that,
+// the chance keeps rising. If key is nil, values are compared directly, as
+// with Distinct.
+func DistinctBloom(capacity uint, fpRate float64, key Mapper) Transducer {
+	if capacity < 1 {
+		panic("Bloom filter capacity must be at least one")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("false positive rate must be between 0 and 1")
+	}
+	if key == nil {
+		key = identity
+	}
+
+	// the standard optimal sizing
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(capacity)*math.Ln2)))
+
+	return func(r Reducer) Reducer {
+		return &distinctBloom{
+			reducerBase: reducerBase{r},
+			key:         key,
+			seed:        maphash.MakeSeed(),
+			bits:        make([]uint64, (m+63)/64),
+			m:           m,
+			hashes:      k,
+		}
+	}
+}
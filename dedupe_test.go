@@ -0,0 +1,92 @@
+package transducers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDistinctNonComparable(t *testing.T) {
+	src := toi([]int{1, 2}, map[string]int{"a": 1}, []int{1, 2}, []int{2, 1}, map[string]int{"a": 1}, 3, 3)
+	result := ToSlice(Eduction(src, Distinct()))
+	if len(result) != 4 {
+		t.Error("Expected 4 distinct values, got", result)
+	}
+}
+
+func TestDistinctMixedTypes(t *testing.T) {
+	// == says 1, 1.0 and int64(1) are all different, in slices or not
+	src := toi([]interface{}{1}, []interface{}{1.0}, []interface{}{int64(1)}, []interface{}{1},
+		map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1})
+	result := ToSlice(Eduction(src, Distinct()))
+	if len(result) != 5 {
+		t.Error("Expected 5 distinct values, got", result)
+	}
+
+	freqs := Transduce(src, Frequencies()).(map[interface{}]int)
+	if len(freqs) != 5 {
+		t.Error("Expected 5 distinct keys, got", freqs)
+	}
+}
+
+func TestDistinctBy(t *testing.T) {
+	type user struct {
+		id   int
+		tags []string
+	}
+	src := toi(user{1, nil}, user{2, []string{"x"}}, user{1, []string{"y"}}, user{3, nil})
+	ids := func(v interface{}) interface{} {
+		return v.(user).id
+	}
+
+	streamEquals(toi(1, 2, 3), Eduction(src, DistinctBy(ids), Map(ids)), t)
+}
+
+func TestDistinctLRU(t *testing.T) {
+	src := []int{1, 2, 1, 3, 1, 4, 2, 2, 5, 1}
+	// 1 stays fresh from being seen; 2 falls out of the window
+	result := Transduce(src, tb(), DistinctLRU(2, nil)).([]int)
+	intSliceEquals([]int{1, 2, 3, 4, 2, 5, 1}, result, t)
+}
+
+func TestDistinctBloom(t *testing.T) {
+	n := 1000
+	result := Transduce(Range(n), tb(), Mapcat(func(v interface{}) ValueStream {
+		return ToStream([]int{v.(int), v.(int)})
+	}), DistinctBloom(uint(n), 0.01, nil)).([]int)
+
+	// no duplicates get through, and very few false positives are dropped
+	seen := make(map[int]bool)
+	for _, v := range result {
+		if seen[v] {
+			t.Fatal("Duplicate got through:", v)
+		}
+		seen[v] = true
+	}
+	if len(result) < n*95/100 {
+		t.Error("Too many false positives:", n-len(result))
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	result := Transduce([]int{1, 1, 2, 1, 1, 3, 3, 3, 2}, tb(), Dedupe()).([]int)
+	intSliceEquals([]int{1, 2, 1, 3, 2}, result, t)
+
+	evens := func(v interface{}) interface{} {
+		return v.(int)%2 == 0
+	}
+	result = Transduce([]int{1, 3, 2, 4, 5, 6}, tb(), DedupeBy(evens)).([]int)
+	intSliceEquals([]int{1, 2, 5, 6}, result, t)
+}
+
+func TestDedupeStreams(t *testing.T) {
+	// chunks are compared by contents, and still arrive intact
+	chunks := ToSlice(Eduction([]int{1, 2, 1, 2, 1, 3, 1, 2}, Chunk(2), Dedupe()))
+	if fmt.Sprint(chunks) != "[[1 2] [1 3] [1 2]]" {
+		t.Error("Unexpected chunks", chunks)
+	}
+
+	chunks = ToSlice(Eduction([]int{1, 2, 1, 2, 1, 3, 1, 2}, Chunk(2), Distinct()))
+	if fmt.Sprint(chunks) != "[[1 2] [1 3]]" {
+		t.Error("Unexpected chunks", chunks)
+	}
+}
@@ -0,0 +1,296 @@
+package transducers
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotSupported is wrapped by errors reporting a value or collection of a
+// type that something (ToStreamErr, AppendErr) doesn't know how to handle.
+var ErrNotSupported = errors.New("not supported")
+
+// ErrReducer is the error-carrying counterpart to Reducer. Its reducing
+// step can fail without panicking: a non-nil error means the value could not
+// be reduced, and was dropped.
+//
+// An error does not imply termination - that's still up to the bool. Whether
+// the process carries on after an error is up to the processor (see
+// TransduceErr and TransduceErrs).
+type ErrReducer interface {
+	Step(accum interface{}, value interface{}) (result interface{}, terminate bool, err error)
+	Complete(accum interface{}) (result interface{}, err error)
+	Init() interface{}
+}
+
+// ErrTransducer is the error-carrying counterpart to Transducer.
+//
+// Plain transducers can be used in an ErrTransducer stack by wrapping them
+// with Lift.
+type ErrTransducer func(ErrReducer) ErrReducer
+
+// Creates an error-carrying transduction pipeline from a bottom reducer and a
+// stack of ErrTransducers. Same deal as CreatePipeline.
+func CreateErrPipeline(r ErrReducer, tds ...ErrTransducer) (rs ErrReducer) {
+	rs = r
+	for i := len(tds) - 1; i >= 0; i-- {
+		rs = tds[i](rs)
+	}
+
+	return
+}
+
+// Lift adapts a plain transducer for use in an error-carrying stack.
+//
+// Errors from further down the pipeline are collected as they pass back up
+// through the plain transducer, and reported out the top along with the
+// result of the step that produced them. If the plain transducer sends more
+// than one value down during a single step (e.g. Mapcat), each of those values
+// is still sent, and all of their errors are reported together.
+func Lift(td Transducer) ErrTransducer {
+	return func(next ErrReducer) ErrReducer {
+		cell := new(errCell)
+		return lifted{td(errLink{next, cell}), cell}
+	}
+}
+
+// errCell holds errors that have been reported up through a plain
+// transducer, until they can be passed out the top of it.
+type errCell struct {
+	errs stepErrors
+}
+
+func (c *errCell) add(err error) {
+	if se, ok := err.(stepErrors); ok {
+		c.errs = append(c.errs, se...)
+	} else if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+func (c *errCell) take() error {
+	defer func() { c.errs = nil }()
+
+	switch len(c.errs) {
+	case 0:
+		return nil
+	case 1:
+		return c.errs[0]
+	default:
+		return c.errs
+	}
+}
+
+// stepErrors is what's reported when more than one error arose during a
+// single step.
+type stepErrors []error
+
+func (e stepErrors) Error() string {
+	msgs := make([]string, len(e))
+	for k, err := range e {
+		msgs[k] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e stepErrors) Unwrap() []error {
+	return e
+}
+
+// Sits at the top of a lifted plain transducer, reporting any errors that
+// made it up through the plain transducer.
+type lifted struct {
+	r    Reducer
+	cell *errCell
+}
+
+func (r lifted) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	accum, terminate := r.r.Step(accum, value)
+	return accum, terminate, r.cell.take()
+}
+
+func (r lifted) Complete(accum interface{}) (interface{}, error) {
+	accum = r.r.Complete(accum)
+	return accum, r.cell.take()
+}
+
+func (r lifted) Init() interface{} {
+	return r.r.Init()
+}
+
+// Sits at the bottom of a lifted plain transducer, catching errors from the
+// error-carrying reducer below it.
+type errLink struct {
+	next ErrReducer
+	cell *errCell
+}
+
+func (r errLink) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	accum, terminate, err := r.next.Step(accum, value)
+	r.cell.add(err)
+	return accum, terminate
+}
+
+func (r errLink) Complete(accum interface{}) interface{} {
+	accum, err := r.next.Complete(accum)
+	r.cell.add(err)
+	return accum
+}
+
+func (r errLink) Init() interface{} {
+	return r.next.Init()
+}
+
+// ErrAware adapts a plain reducer (e.g. a bottom reducer) into an
+// ErrReducer that never fails.
+func ErrAware(r Reducer) ErrReducer {
+	return errAware{r}
+}
+
+type errAware struct {
+	r Reducer
+}
+
+func (r errAware) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	accum, terminate := r.r.Step(accum, value)
+	return accum, terminate, nil
+}
+
+func (r errAware) Complete(accum interface{}) (interface{}, error) {
+	return r.r.Complete(accum), nil
+}
+
+func (r errAware) Init() interface{} {
+	return r.r.Init()
+}
+
+type append_err struct {
+	append_bottom
+}
+
+func (r append_err) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	accum, err := appendInts(accum.([]int), value)
+	return accum, false, err
+}
+
+func (r append_err) Complete(accum interface{}) (interface{}, error) {
+	return accum, nil
+}
+
+// AppendErr is the error-carrying version of Append. Rather than panicking on
+// a value it can't append, it returns an error wrapping ErrNotSupported.
+func AppendErr() ErrReducer {
+	return append_err{}
+}
+
+// Most ErrTransducers need to pass Complete and Init through untouched.
+type errReducerBase struct {
+	next ErrReducer
+}
+
+func (r errReducerBase) Complete(accum interface{}) (interface{}, error) {
+	return r.next.Complete(accum)
+}
+
+func (r errReducerBase) Init() interface{} {
+	return r.next.Init()
+}
+
+type mapErr struct {
+	errReducerBase
+	f ErrMapper
+}
+
+func (r mapErr) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	nv, err := r.f(value)
+	if err != nil {
+		return accum, false, err
+	}
+	return r.next.Step(accum, nv)
+}
+
+// MapErr is Map for predicates that can fail. If the predicate returns an
+// error, the value is dropped and the error is reported.
+func MapErr(f ErrMapper) ErrTransducer {
+	return func(r ErrReducer) ErrReducer {
+		return mapErr{errReducerBase{r}, f}
+	}
+}
+
+type filterErr struct {
+	errReducerBase
+	f ErrFilterer
+}
+
+func (r filterErr) Step(accum interface{}, value interface{}) (interface{}, bool, error) {
+	var check bool
+	var err error
+	if vs, ok := value.(ValueStream); ok {
+		vs, value = vs.Split()
+		check, err = r.f(vs)
+	} else {
+		check, err = r.f(value)
+	}
+
+	if err != nil {
+		return accum, false, err
+	}
+	if check {
+		return r.next.Step(accum, value)
+	}
+	return accum, false, nil
+}
+
+// FilterErr is Filter for predicates that can fail. If the predicate returns
+// an error, the value is dropped and the error is reported.
+func FilterErr(f ErrFilterer) ErrTransducer {
+	return func(r ErrReducer) ErrReducer {
+		return filterErr{errReducerBase{r}, f}
+	}
+}
+
+// TransduceErr is Transduce for error-carrying stacks. It stops at the first
+// error - no more values are stepped through, but Complete is still called
+// - and returns the result along with that error.
+//
+// If the collection can't be streamed, it returns that error without doing
+// anything else.
+func TransduceErr(coll interface{}, bottom ErrReducer, tlist ...ErrTransducer) (interface{}, error) {
+	ret, errs := transduceErr(coll, bottom, true, tlist...)
+	if len(errs) != 0 {
+		return ret, errs[0]
+	}
+	return ret, nil
+}
+
+// TransduceErrs is Transduce for error-carrying stacks. It carries on past
+// errors, dropping the values that caused them, and returns the result along
+// with every error that occurred, in order.
+func TransduceErrs(coll interface{}, bottom ErrReducer, tlist ...ErrTransducer) (interface{}, []error) {
+	return transduceErr(coll, bottom, false, tlist...)
+}
+
+func transduceErr(coll interface{}, bottom ErrReducer, failFast bool, tlist ...ErrTransducer) (interface{}, []error) {
+	vs, release, err := streamOfErr(coll)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var errs errCell
+	t := CreateErrPipeline(bottom, tlist...)
+	var ret interface{} = t.Init()
+	var terminate bool
+
+	for v, done := vs(); !done; v, done = vs() {
+		ret, terminate, err = t.Step(ret, v)
+		errs.add(err)
+		if terminate || (failFast && len(errs.errs) != 0) {
+			break
+		}
+	}
+
+	release()
+	ret, err = t.Complete(ret)
+	errs.add(err)
+
+	return ret, errs.errs
+}
@@ -0,0 +1,81 @@
+package transducers
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func atoi(value interface{}) (interface{}, error) {
+	return strconv.Atoi(value.(string))
+}
+
+func TestTransduceErr(t *testing.T) {
+	input := toi("1", "2", "three", "4", "five")
+
+	result, err := TransduceErr(input, AppendErr(), MapErr(atoi), Lift(Map(Inc)))
+	intSliceEquals([]int{2, 3}, result.([]int), t)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("Expected the parse error for 'three', got", err)
+	}
+
+	result, errs := TransduceErrs(input, AppendErr(), MapErr(atoi), Lift(Map(Inc)))
+	intSliceEquals([]int{2, 3, 5}, result.([]int), t)
+	if len(errs) != 2 {
+		t.Fatal("Expected two errors, got", errs)
+	}
+	if errs[0].(*strconv.NumError).Num != "three" || errs[1].(*strconv.NumError).Num != "five" {
+		t.Error("Errors reported out of order:", errs)
+	}
+
+	result, err = TransduceErr(toi("1", "2"), AppendErr(), MapErr(atoi))
+	intSliceEquals([]int{1, 2}, result.([]int), t)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestLiftedErrors(t *testing.T) {
+	evenErr := func(value interface{}) (bool, error) {
+		if value.(int) == 3 {
+			return false, errors.New("three")
+		}
+		return Even(value), nil
+	}
+
+	// errors make it back up through plain transducers, including ones that
+	// send multiple values down in one step
+	xf := []ErrTransducer{Lift(Mapcat(Range)), FilterErr(evenErr), Lift(Chunk(2)), Lift(Mapcat(Flatten))}
+	result, errs := TransduceErrs([]int{4, 5}, AppendErr(), xf...)
+	intSliceEquals([]int{0, 2, 0, 2, 4}, result.([]int), t)
+	if len(errs) != 2 {
+		t.Error("Expected two errors, got", errs)
+	}
+
+	// termination still works through a lifted transducer
+	result, err := TransduceErr(Range(10), AppendErr(), FilterErr(evenErr), Lift(Take(2)))
+	intSliceEquals([]int{0, 2}, result.([]int), t)
+	if err != nil {
+		t.Error("Take should have terminated before reaching the error, got", err)
+	}
+}
+
+func TestNotSupportedErrors(t *testing.T) {
+	result, err := TransduceErr(toi(1, "two", 3), AppendErr())
+	intSliceEquals([]int{1}, result.([]int), t)
+	if !errors.Is(err, ErrNotSupported) {
+		t.Error("Expected ErrNotSupported from AppendErr, got", err)
+	}
+
+	// a stream with a bad value in it is dropped whole
+	result, errs := TransduceErrs(toi(1, ToStream(toi(2, "three")), 4), AppendErr())
+	intSliceEquals([]int{1, 4}, result.([]int), t)
+	if len(errs) != 1 {
+		t.Error("Expected one error from the bad stream, got", errs)
+	}
+
+	_, err = TransduceErr(42, ErrAware(Append()))
+	if !errors.Is(err, ErrNotSupported) {
+		t.Error("Expected ErrNotSupported for an unstreamable collection, got", err)
+	}
+}
@@ -0,0 +1,258 @@
+package transducers
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// A Watermarker tracks how far along in event time a stream has got. Its
+// watermark is a promise that no more values with timestamps before it are
+// expected; any that do turn up are late.
+type Watermarker interface {
+	// Observe is called with the timestamp of each value, before the watermark
+	// is checked.
+	Observe(ts time.Time)
+	// Watermark returns the current watermark. It should never go backwards.
+	Watermark() time.Time
+}
+
+// A WatermarkStrategy creates a fresh Watermarker for each pipeline.
+type WatermarkStrategy func() Watermarker
+
+// BoundedOutOfOrderness is a watermark strategy for values that arrive out of
+// order, but by no more than maxDelay: the watermark trails the latest
+// timestamp seen by maxDelay. With a maxDelay of zero, timestamps must be in
+// order, or they're late.
+func BoundedOutOfOrderness(maxDelay time.Duration) WatermarkStrategy {
+	return func() Watermarker {
+		return &boundedWatermark{delay: maxDelay}
+	}
+}
+
+type boundedWatermark struct {
+	delay time.Duration
+	max   time.Time
+	any   bool
+}
+
+func (w *boundedWatermark) Observe(ts time.Time) {
+	if !w.any || ts.After(w.max) {
+		w.max, w.any = ts, true
+	}
+}
+
+func (w *boundedWatermark) Watermark() time.Time {
+	if !w.any {
+		return time.Time{}
+	}
+	return w.max.Add(-w.delay)
+}
+
+// A WindowAssigner determines which windows each value belongs to, by its
+// timestamp. See TumblingWindows, SlidingWindows and SessionWindows.
+type WindowAssigner struct {
+	size, slide, gap time.Duration
+}
+
+// TumblingWindows assigns each value to exactly one window of the given size.
+// Windows are aligned to the Unix epoch, so with a size of an hour, they start
+// on the hour.
+func TumblingWindows(size time.Duration) WindowAssigner {
+	return SlidingWindows(size, size)
+}
+
+// SlidingWindows assigns each value to every window of the given size that
+// contains its timestamp, with a window starting every slide. As with
+// TumblingWindows, they're aligned to the Unix epoch.
+func SlidingWindows(size, slide time.Duration) WindowAssigner {
+	if size <= 0 || slide <= 0 {
+		panic("window size and slide must be positive")
+	}
+	return WindowAssigner{size: size, slide: slide}
+}
+
+// SessionWindows groups values into sessions of activity: a session collects
+// values until there's a gap of at least the given length between them.
+func SessionWindows(gap time.Duration) WindowAssigner {
+	if gap <= 0 {
+		panic("session gap must be positive")
+	}
+	return WindowAssigner{gap: gap}
+}
+
+// The windows (before any session merging) a timestamp belongs in.
+func (a WindowAssigner) assign(ts int64) []span {
+	if a.gap > 0 {
+		return []span{{ts, ts + int64(a.gap)}}
+	}
+
+	var spans []span
+	size, slide := int64(a.size), int64(a.slide)
+	last := ts - ((ts%slide)+slide)%slide // floor, even for negative timestamps
+	for start := last; start > ts-size; start -= slide {
+		spans = append(spans, span{start, start + size})
+	}
+	return spans
+}
+
+// An EventWindow is a window of values, passed along by EventWindows once
+// the watermark passes its end. It covers the timestamps in [Start, End).
+//
+// The values are kept in the order they arrived - except in sessions that were
+// merged, which have each original session's values in turn. EventWindows are
+// Streamable, so they can be passed to a processor, or to ToStream, to get at
+// the values.
+type EventWindow struct {
+	Start, End time.Time
+	Values     []interface{}
+}
+
+func (w EventWindow) AsStream() ValueStream {
+	return valueSlice(w.Values).AsStream()
+}
+
+// Bounds of a window, in Unix nanoseconds.
+type span struct {
+	start, end int64
+}
+
+type eventWindows struct {
+	reducerBase
+	assigner  WindowAssigner
+	timestamp Mapper
+	wm        Watermarker
+	late      chan<- interface{}
+	coc       bool
+	open      map[span][]interface{}
+	terminate bool
+}
+
+func (r *eventWindows) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	ts := r.timestamp(value).(time.Time).UnixNano()
+	wm := r.wm.Watermark().UnixNano()
+
+	spans := r.assigner.assign(ts)
+	if r.assigner.gap > 0 {
+		spans = r.merge(spans[0])
+	}
+
+	var added bool
+	for _, s := range spans {
+		if s.end > wm {
+			r.open[s] = append(r.open[s], value)
+			added = true
+		}
+	}
+
+	if !added {
+		// every window it belongs in has already been passed along
+		if r.late != nil {
+			r.late <- value
+		}
+		return accum, false
+	}
+
+	r.wm.Observe(time.Unix(0, ts))
+	return r.fire(accum, r.wm.Watermark().UnixNano())
+}
+
+// Merges a new session with any open sessions it overlaps, returning the
+// resulting session.
+func (r *eventWindows) merge(s span) []span {
+	var overlaps []span
+	for o := range r.open {
+		if o.start < s.end && s.start < o.end {
+			overlaps = append(overlaps, o)
+		}
+	}
+	if len(overlaps) == 0 {
+		return []span{s}
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool {
+		return overlaps[i].start < overlaps[j].start
+	})
+
+	merged := s
+	var values []interface{}
+	for _, o := range overlaps {
+		if o.start < merged.start {
+			merged.start = o.start
+		}
+		if o.end > merged.end {
+			merged.end = o.end
+		}
+		values = append(values, r.open[o]...)
+		delete(r.open, o)
+	}
+
+	r.open[merged] = values
+	return []span{merged}
+}
+
+// Passes along all the open windows ending at or before wm, in order.
+func (r *eventWindows) fire(accum interface{}, wm int64) (interface{}, bool) {
+	var due []span
+	for s := range r.open {
+		if s.end <= wm {
+			due = append(due, s)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].end != due[j].end {
+			return due[i].end < due[j].end
+		}
+		return due[i].start < due[j].start
+	})
+
+	for _, s := range due {
+		w := EventWindow{time.Unix(0, s.start), time.Unix(0, s.end), r.open[s]}
+		delete(r.open, s)
+		accum, r.terminate = r.next.Step(accum, w)
+		if r.terminate {
+			break
+		}
+	}
+
+	return accum, r.terminate
+}
+
+func (r *eventWindows) Complete(accum interface{}) interface{} {
+	if !r.terminate {
+		// the input's over, so every window is too
+		accum, _ = r.fire(accum, math.MaxInt64)
+	}
+
+	if r.coc && r.late != nil {
+		close(r.late)
+	}
+	return r.next.Complete(accum)
+}
+
+// EventWindows groups values into windows by their event time - the timestamp
+// (a time.Time) that the given func returns for each of them - rather than
+// the time they arrive. Windows are passed along, as EventWindows, once the
+// watermark passes their end.
+//
+// Values that turn up after the watermark has passed the end of every window
+// they belong in are late. They're sent to the late channel, in the manner of
+// Escape; if it's nil, they're dropped. If closeOnComplete is true, the late
+// channel is closed on Complete.
+//
+// On Complete, any windows still open are passed along, regardless of the
+// watermark.
+func EventWindows(assigner WindowAssigner, timestamp Mapper, watermarks WatermarkStrategy, late chan<- interface{}, closeOnComplete bool) Transducer {
+	return func(r Reducer) Reducer {
+		return &eventWindows{
+			reducerBase: reducerBase{r},
+			assigner:    assigner,
+			timestamp:   timestamp,
+			wm:          watermarks(),
+			late:        late,
+			coc:         closeOnComplete,
+			open:        make(map[span][]interface{}),
+		}
+	}
+}
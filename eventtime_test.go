@@ -0,0 +1,87 @@
+package transducers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type reading struct {
+	at    int // seconds
+	value int
+}
+
+func readingTime(v interface{}) interface{} {
+	return time.Unix(int64(v.(reading).at), 0)
+}
+
+// Summarizes windows as "start-end:[values...]", in seconds.
+func windowString(v interface{}) interface{} {
+	w := v.(EventWindow)
+	var vals []int
+	for _, r := range w.Values {
+		vals = append(vals, r.(reading).value)
+	}
+	return fmt.Sprintf("%d-%d:%v", w.Start.Unix(), w.End.Unix(), vals)
+}
+
+func readings(at ...int) []interface{} {
+	var rs []interface{}
+	for k, a := range at {
+		rs = append(rs, reading{a, k})
+	}
+	return rs
+}
+
+func TestEventWindowsTumbling(t *testing.T) {
+	late := make(chan interface{}, 10)
+	xf := []Transducer{
+		EventWindows(TumblingWindows(10*time.Second), readingTime, BoundedOutOfOrderness(5*time.Second), late, true),
+		Map(windowString),
+	}
+
+	// 3 is out of order, but within bounds; 4 is too late
+	src := readings(1, 8, 12, 9, 16, 22, 5, 31)
+	result := fmt.Sprint(ToSlice(Eduction(src, xf...)))
+	if result != "[0-10:[0 1 3] 10-20:[2 4] 20-30:[5] 30-40:[7]]" {
+		t.Error("Unexpected windows", result)
+	}
+
+	var lates []interface{}
+	for v := range late {
+		lates = append(lates, v)
+	}
+	if fmt.Sprint(lates) != "[{5 6}]" {
+		t.Error("Unexpected late values", lates)
+	}
+}
+
+func TestEventWindowsSliding(t *testing.T) {
+	xf := []Transducer{
+		EventWindows(SlidingWindows(10*time.Second, 5*time.Second), readingTime, BoundedOutOfOrderness(0), nil, false),
+		Map(windowString),
+	}
+
+	result := fmt.Sprint(ToSlice(Eduction(readings(1, 6, 12), xf...)))
+	if result != "[-5-5:[0] 0-10:[0 1] 5-15:[1 2] 10-20:[2]]" {
+		t.Error("Unexpected windows", result)
+	}
+}
+
+func TestEventWindowsSession(t *testing.T) {
+	late := make(chan interface{}, 10)
+	xf := []Transducer{
+		EventWindows(SessionWindows(5*time.Second), readingTime, BoundedOutOfOrderness(10*time.Second), late, false),
+		Map(windowString),
+	}
+
+	// 7 bridges the gap between the sessions around 3 and 11
+	src := readings(1, 3, 11, 7, 30, 40, 2, 50)
+	result := fmt.Sprint(ToSlice(Eduction(src, xf...)))
+	if result != "[1-16:[0 1 2 3] 30-35:[4] 40-45:[5] 50-55:[7]]" {
+		t.Error("Unexpected windows", result)
+	}
+	if len(late) != 1 {
+		t.Error("Expected one late value, got", len(late))
+	}
+}
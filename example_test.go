@@ -52,8 +52,8 @@ func Example_clojureParity() {
 	// NB: the original clojure gists also have (sequence ...) and (into []...)
 	// processors. I didn't replicate `sequence` because, as best I can figure,
 	// it's redundant with Eduction in the context I've created (no seqs).
-	// I didn't replicate `into` because it's a use pattern that is awkward with
-	// static typing, and is readily accomplished via Transduce.
+	// `into` is just Transduce with one of the Into* bottom reducers, e.g.
+	// Transduce(data, IntoSliceOf[int](), xform...).
 
 	// reduce immediately, appending the results of transduction into an int slice.
 	fmt.Println(Transduce(data, Append(), xform...))
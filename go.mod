@@ -0,0 +1,3 @@
+module github.com/sdboyer/transducers-go
+
+go 1.24
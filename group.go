@@ -0,0 +1,111 @@
+package transducers
+
+// An UnhashableKey stands in for a key that can't be used as a map key (a
+// slice, say), in the maps returned by GroupBy and Frequencies. There's one
+// for each distinct key, holding the first value that was seen as that key; to
+// get at it, use a type switch on the map's keys.
+type UnhashableKey struct {
+	Value interface{}
+}
+
+// Returns the key to use in a result map for a key with the given hashKey.
+func resultKey(k, hashed interface{}) interface{} {
+	if _, ok := hashed.(printedKey); ok {
+		return &UnhashableKey{k}
+	}
+	return k
+}
+
+// A group's own pipeline, and its accumulator.
+type group struct {
+	key  interface{}
+	pipe Reducer
+	acc  interface{}
+	done bool // the group's pipeline terminated
+}
+
+type groupBy struct {
+	key   Mapper
+	sub   Reducer
+	xform []Transducer
+}
+
+func (r groupBy) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	k, value := rawKeyOf(r.key, value)
+	hashed := hashKey(k)
+	groups := accum.(map[interface{}]*group)
+	g, has := groups[hashed]
+	if !has {
+		g = &group{key: resultKey(k, hashed), pipe: CreatePipeline(r.sub, r.xform...)}
+		g.acc = g.pipe.Init()
+		groups[hashed] = g
+	}
+
+	if !g.done {
+		g.acc, g.done = g.pipe.Step(g.acc, value)
+	}
+	return accum, false
+}
+
+func (r groupBy) Complete(accum interface{}) interface{} {
+	result := make(map[interface{}]interface{})
+	for _, g := range accum.(map[interface{}]*group) {
+		result[g.key] = g.pipe.Complete(g.acc)
+	}
+	return result
+}
+
+func (r groupBy) Init() interface{} {
+	return make(map[interface{}]*group)
+}
+
+// GroupBy is a bottom reducer that splits values up into groups, by the key
+// the given func returns for each of them, and reduces each group separately.
+//
+// Each group gets its own pipeline, created from the sub reducer and the xform
+// stack, just as a processor would - so stateful transducers in the stack
+// (Take, Dedupe, etc.) apply to each group on its own. When a group's pipeline
+// terminates, later values for that group are dropped.
+//
+// On Complete, each group's pipeline is completed, and the result is a
+// map[interface{}]interface{} from each key to what its group reduced to.
+//
+// Keys that can't be map keys (slices, maps - and ValueStreams, which are read
+// into slices) are compared as with Distinct: by their contents, including the
+// type of each element. In the result, each one is held in an UnhashableKey.
+func GroupBy(key Mapper, sub Reducer, xform ...Transducer) Reducer {
+	return groupBy{key, sub, xform}
+}
+
+type frequencies struct {
+	counts map[interface{}]int
+	// the UnhashableKeys in counts, by their hashKey
+	unhashable map[printedKey]*UnhashableKey
+}
+
+// Frequencies is a bottom reducer that counts how many times each distinct
+// value reaches it, as a map[interface{}]int. Values that can't be map keys
+// are counted by their contents, and held in UnhashableKeys, as with GroupBy.
+func Frequencies() Reducer {
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		f := accum.(frequencies)
+		k, _ := rawKeyOf(identity, value)
+		if hashed, ok := hashKey(k).(printedKey); ok {
+			if _, has := f.unhashable[hashed]; !has {
+				f.unhashable[hashed] = &UnhashableKey{k}
+			}
+			k = f.unhashable[hashed]
+		}
+
+		f.counts[k]++
+		return accum, false
+	})
+	r.C = func(accum interface{}) interface{} {
+		return accum.(frequencies).counts
+	}
+	r.I = func() interface{} {
+		return frequencies{make(map[interface{}]int), make(map[printedKey]*UnhashableKey)}
+	}
+
+	return r
+}
@@ -0,0 +1,85 @@
+package transducers
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	parity := func(v interface{}) interface{} {
+		if Even(v) {
+			return "even"
+		}
+		return "odd"
+	}
+
+	gb := GroupBy(parity, tb(), Map(Inc), Take(3))
+	result := Transduce(Range(10), gb).(map[interface{}]interface{})
+	if fmt.Sprint(result) != "map[even:[1 3 5] odd:[2 4 6]]" {
+		t.Error("Unexpected groups", result)
+	}
+
+	// each run gets fresh groups
+	result = Transduce(Range(4), gb).(map[interface{}]interface{})
+	if fmt.Sprint(result) != "map[even:[1 3] odd:[2 4]]" {
+		t.Error("Unexpected groups", result)
+	}
+
+	sums := Transduce(Range(10), GroupBy(parity, Total())).(map[interface{}]interface{})
+	if sums["even"] != int64(20) || sums["odd"] != int64(25) {
+		t.Error("Unexpected sums", sums)
+	}
+}
+
+func TestGroupByStreams(t *testing.T) {
+	size := func(v interface{}) interface{} {
+		return len(ToSlice(v.(ValueStream)))
+	}
+
+	result := Transduce(Range(7), GroupBy(size, Count()), Chunk(3)).(map[interface{}]interface{})
+	if result[3] != 6 || result[1] != 1 {
+		t.Error("Unexpected groups", result)
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	result := Transduce(toi("a", "b", "a", "c", "a", "b"), Frequencies()).(map[interface{}]int)
+	if fmt.Sprint(result) != "map[a:3 b:2 c:1]" {
+		t.Error("Unexpected frequencies", result)
+	}
+}
+
+func TestUnhashableKeys(t *testing.T) {
+	freqs := Transduce(toi(0, 1, 0, 1, 2), Frequencies(), Chunk(2)).(map[interface{}]int)
+	var counts []string
+	for k, n := range freqs {
+		counts = append(counts, fmt.Sprint(k.(*UnhashableKey).Value, n))
+	}
+	sort.Strings(counts)
+	if fmt.Sprint(counts) != "[[0 1] 2 [2] 1]" {
+		t.Error("Expected chunks [0 1] twice and [2] once, got", counts)
+	}
+
+	digits := func(v interface{}) interface{} {
+		return []int{v.(int) % 2, v.(int) % 3}
+	}
+	groups := Transduce(Range(12), GroupBy(digits, Count())).(map[interface{}]interface{})
+	var keys []string
+	for k, n := range groups {
+		keys = append(keys, fmt.Sprint(k.(*UnhashableKey).Value))
+		if n != 2 {
+			t.Error("Unexpected group sizes", groups)
+		}
+	}
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != "[[0 0] [0 1] [0 2] [1 0] [1 1] [1 2]]" {
+		t.Error("Unexpected group keys", keys)
+	}
+
+	// hashable keys are left as they are
+	mixed := Transduce(toi(1, []int{1}, 1), Frequencies()).(map[interface{}]int)
+	if mixed[1] != 2 || len(mixed) != 2 {
+		t.Error("Unexpected frequencies", mixed)
+	}
+}
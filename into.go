@@ -0,0 +1,150 @@
+package transducers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IntoSliceOf is a bottom reducer that appends values into a []T. Values that
+// are ValueStreams are flattened into their values, unless a ValueStream is
+// itself a T (as it is when T is ValueStream, or interface{}) - then it's
+// appended whole.
+//
+// Panics if a value isn't a T.
+func IntoSliceOf[T any]() Reducer {
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		into := accum.([]T)
+		if _, ok := value.(T); !ok {
+			if vs, ok := value.(ValueStream); ok {
+				vs.Flatten().Each(func(v interface{}) {
+					into = append(into, v.(T))
+				})
+				return into, false
+			}
+		}
+
+		return append(into, value.(T)), false
+	})
+	r.I = func() interface{} {
+		return make([]T, 0)
+	}
+
+	return r
+}
+
+// IntoMap is a bottom reducer that puts values into a map[K]V, under the key
+// and as the value the given funcs extract from each of them.
+//
+// When two values have the same key, the merge func is called with the value
+// already in the map and the new one, and its result is kept. If it's nil,
+// the new one replaces the old (as with KeepLast).
+func IntoMap[K comparable, V any](key func(interface{}) K, val func(interface{}) V, merge func(existing, incoming V) V) Reducer {
+	if merge == nil {
+		merge = KeepLast[V]
+	}
+
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		into := accum.(map[K]V)
+		k, v := key(value), val(value)
+		if existing, has := into[k]; has {
+			v = merge(existing, v)
+		}
+		into[k] = v
+		return into, false
+	})
+	r.I = func() interface{} {
+		return make(map[K]V)
+	}
+
+	return r
+}
+
+// KeepFirst is a merge func for IntoMap that keeps the first value seen for
+// each key.
+func KeepFirst[V any](existing, incoming V) V {
+	return existing
+}
+
+// KeepLast is a merge func for IntoMap that keeps the last value seen for each
+// key.
+func KeepLast[V any](existing, incoming V) V {
+	return incoming
+}
+
+// IntoSet is a bottom reducer that puts values into a set, represented as a
+// map[T]struct{}. ValueStreams are flattened, as with IntoSliceOf.
+//
+// Panics if a value isn't a T.
+func IntoSet[T comparable]() Reducer {
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		into := accum.(map[T]struct{})
+		if _, ok := value.(T); !ok {
+			if vs, ok := value.(ValueStream); ok {
+				vs.Flatten().Each(func(v interface{}) {
+					into[v.(T)] = struct{}{}
+				})
+				return into, false
+			}
+		}
+
+		into[value.(T)] = struct{}{}
+		return into, false
+	})
+	r.I = func() interface{} {
+		return make(map[T]struct{})
+	}
+
+	return r
+}
+
+// Accumulator for IntoString.
+type stringAccum struct {
+	b strings.Builder
+	n int
+}
+
+// IntoString is a bottom reducer that writes values into a string, with sep
+// between each of them. Strings and runes are written as-is; anything else is
+// written as fmt.Print would. ValueStreams are flattened.
+//
+// rune is an alias for int32, so int32s are written as the characters they
+// encode, not as numbers - as they come from streaming a string. Map them to
+// another int type first to get numbers.
+func IntoString(sep string) Reducer {
+	var write func(s *stringAccum, value interface{})
+	write = func(s *stringAccum, value interface{}) {
+		if vs, ok := value.(ValueStream); ok {
+			vs.Flatten().Each(func(v interface{}) {
+				write(s, v)
+			})
+			return
+		}
+
+		if s.n > 0 {
+			s.b.WriteString(sep)
+		}
+		s.n++
+
+		switch v := value.(type) {
+		case string:
+			s.b.WriteString(v)
+		case rune:
+			s.b.WriteRune(v)
+		default:
+			fmt.Fprint(&s.b, v)
+		}
+	}
+
+	r := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		write(accum.(*stringAccum), value)
+		return accum, false
+	})
+	r.C = func(accum interface{}) interface{} {
+		return accum.(*stringAccum).b.String()
+	}
+	r.I = func() interface{} {
+		return &stringAccum{}
+	}
+
+	return r
+}
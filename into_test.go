@@ -0,0 +1,82 @@
+package transducers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIntoSliceOf(t *testing.T) {
+	words := []string{"a", "bb", "ccc"}
+	result := Transduce(words, IntoSliceOf[string](), Map(func(v interface{}) interface{} {
+		return strings.ToUpper(v.(string))
+	}))
+	if fmt.Sprint(result) != "[A BB CCC]" {
+		t.Error("Unexpected result", result)
+	}
+
+	chunks := Transduce(Range(5), IntoSliceOf[int](), Chunk(2)).([]int)
+	intSliceEquals([]int{0, 1, 2, 3, 4}, chunks, t)
+
+	streams := Transduce(Range(5), IntoSliceOf[ValueStream](), Chunk(2)).([]ValueStream)
+	if len(streams) != 3 {
+		t.Error("Streams should be kept whole in a []ValueStream, got", len(streams))
+	}
+	if anys := Transduce(Range(5), IntoSliceOf[interface{}](), Chunk(2)).([]interface{}); len(anys) != 3 {
+		t.Error("Streams should be kept whole in a []interface{}, got", len(anys))
+	}
+}
+
+func TestIntoMap(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+	first := func(v interface{}) string {
+		return v.(string)[:1]
+	}
+	str := func(v interface{}) string {
+		return v.(string)
+	}
+
+	last := Transduce(words, IntoMap(first, str, nil)).(map[string]string)
+	if fmt.Sprint(last) != "map[a:avocado b:blueberry c:cherry]" {
+		t.Error("Unexpected result", last)
+	}
+
+	kept := Transduce(words, IntoMap(first, str, KeepFirst[string])).(map[string]string)
+	if fmt.Sprint(kept) != "map[a:apple b:banana c:cherry]" {
+		t.Error("Unexpected result", kept)
+	}
+
+	counts := Transduce(words, IntoMap(first, func(interface{}) int { return 1 }, func(a, b int) int {
+		return a + b
+	})).(map[string]int)
+	if fmt.Sprint(counts) != "map[a:2 b:2 c:1]" {
+		t.Error("Unexpected result", counts)
+	}
+}
+
+func TestIntoSet(t *testing.T) {
+	set := Transduce([]int{1, 2, 1, 3, 2}, IntoSet[int]()).(map[int]struct{})
+	if len(set) != 3 {
+		t.Error("Unexpected set", set)
+	}
+}
+
+func TestIntoString(t *testing.T) {
+	if s := Transduce([]string{"a", "", "c"}, IntoString(",")); s != "a,,c" {
+		t.Errorf("Unexpected string %q", s)
+	}
+	if s := Transduce(Range(4), IntoString(" "), Map(Inc)); s != "1 2 3 4" {
+		t.Errorf("Unexpected string %q", s)
+	}
+	if s := Transduce("héllo", IntoString(""), Remove(func(v interface{}) bool {
+		return v == 'l'
+	})); s != "héo" {
+		t.Errorf("Unexpected string %q", s)
+	}
+	if s := Transduce([]int32{65}, IntoString("")); s != "A" {
+		t.Errorf("Expected int32s to be written as runes, got %q", s)
+	}
+	if s := Transduce([]string{}, IntoString(",")); s != "" {
+		t.Errorf("Unexpected string %q", s)
+	}
+}
@@ -1,5 +1,13 @@
 package transducers
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"unicode/utf8"
+)
+
 // ValueStreams are the core abstraction that facilitate value-oriented
 // communication in a transduction pipeline. Unfortunately, various typing
 // issues preclude the use of slices directly.
@@ -15,9 +23,9 @@ package transducers
 // with multiple values will treat a slice as a single value, but a ValueStream
 // as multiple. This is why Exploders return a ValueStream.
 //
-// TODO At minimum, a proper implementation would probably need to include a
-// adding a parameter that allows the caller to indicate they no longer
-// need the stream. (Not quite a 'close', but possibly interpreted that way)
+// A bare ValueStream has no way for a consumer to say it no longer needs any
+// more values. Streams backed by goroutines, files, etc. should be wrapped
+// up in a ReleasableStream, which processors will release when they're done.
 type ValueStream func() (value interface{}, done bool)
 
 // Convenience function that receives from the stream and passes the
@@ -79,58 +87,303 @@ func IntoSlice(vs *ValueStream) (into []interface{}) {
 // Note that calls to the original stream will still work - and any values
 // consumed that way will be missed by the split streams. Be very careful!
 //
-// TODO I think this might leak?
-// TODO figure out if there's a nifty way to make this threadsafe
+// This is a two-way Tee with no limit on how far one stream can get ahead
+// of the other.
 func (vs ValueStream) Split() (ValueStream, ValueStream) {
-	var src ValueStream = vs
-	var f1i, f2i int
-	var held []interface{}
-
-	return func() (value interface{}, done bool) {
-			if f1i >= f2i {
-				// this stream is ahead, pull from the source
-				value, done = src()
-				if !done {
-					// recursively dup streams
-					if vs, ok := value.(ValueStream); ok {
-						vs, value = vs.Split()
-						held = append(held, vs)
-					} else {
-						held = append(held, value)
-					}
-				}
-			} else {
-				value, held = held[0], held[1:]
+	s, _ := vs.Tee(2, 0, LagBlock)
+	return s[0].ValueStream, s[1].ValueStream
+}
+
+// LagPolicy determines what a Tee does when one of its streams gets as far
+// ahead of the slowest as it is allowed to.
+type LagPolicy int
+
+const (
+	// The stream that's ahead blocks until the slowest catches up. Only use
+	// this if the streams are being consumed from different goroutines!
+	LagBlock LagPolicy = iota
+	// The stream that's ahead reports being exhausted, and the Tee's error
+	// func reports ErrLagExceeded.
+	LagError
+	// The oldest buffered value is dropped, so the slowest streams skip it.
+	LagDrop
+)
+
+// ErrLagExceeded is reported by a Tee using LagError when one of its streams
+// gets too far ahead.
+var ErrLagExceeded = errors.New("tee stream exceeded maximum lag")
+
+// Tee splits a value stream into n identical streams that can each be
+// consumed independently, and safely, from different goroutines. Values that
+// are themselves ValueStreams are recursively tee'd in the same way.
+//
+// Values are buffered until every stream has received them. If maxLag is
+// greater than zero, it caps the size of that buffer - how far the fastest
+// stream can get ahead of the slowest - and the policy determines what
+// happens when the cap is hit. Otherwise, the buffer grows without bound.
+//
+// Streams that are released, or that have been read to the end, no longer
+// hold back the buffer. Releasing a stream releases its share of any nested
+// streams, too. The returned func reports an error if one occurred, in this
+// tee or a nested one (only possible under LagError).
+//
+// Note that calls to the original stream will still work - and any values
+// consumed that way will be missed by the tee'd streams. Be very careful!
+func (vs ValueStream) Tee(n int, maxLag int, policy LagPolicy) ([]*ReleasableStream, func() error) {
+	return tee(vs, n, maxLag, policy, func() {})
+}
+
+// Tee tees the stream as ValueStream.Tee does. The source is released once
+// all of the tee'd streams have been released.
+func (s *ReleasableStream) Tee(n int, maxLag int, policy LagPolicy) ([]*ReleasableStream, func() error) {
+	return tee(s.ValueStream, n, maxLag, policy, s.Release)
+}
+
+type teeState struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	src     ValueStream
+	release func()
+	maxLag  int
+	policy  LagPolicy
+	err     error
+
+	held    []interface{} // values not yet received by every stream
+	base    int           // absolute position of held[0]
+	pos     []int         // absolute position of each stream's next value
+	gone    []bool        // streams that are released or finished
+	live    int           // number of streams not yet released
+	pulling bool          // whether some stream is currently pulling from src
+	srcDone bool
+
+	streams []*ReleasableStream
+	// tees of nested streams that are still in play, and the first error from
+	// any that aren't
+	nested    []*teeState
+	nestedErr error
+}
+
+// A value held by a tee that was itself a stream; each tee'd stream gets its own.
+type teedStream []*ReleasableStream
+
+func tee(vs ValueStream, n int, maxLag int, policy LagPolicy, release func()) ([]*ReleasableStream, func() error) {
+	t := newTee(vs, n, maxLag, policy, release)
+	return t.streams, t.error
+}
+
+func newTee(vs ValueStream, n int, maxLag int, policy LagPolicy, release func()) *teeState {
+	if n < 1 {
+		panic("must tee into at least one stream")
+	}
+
+	t := &teeState{
+		src:     vs,
+		release: release,
+		maxLag:  maxLag,
+		policy:  policy,
+		pos:     make([]int, n),
+		gone:    make([]bool, n),
+		live:    n,
+	}
+	t.cond = sync.NewCond(&t.mu)
+
+	t.streams = make([]*ReleasableStream, n)
+	for i := range t.streams {
+		i := i
+		t.streams[i] = Releasable(func() (interface{}, bool) {
+			return t.next(i)
+		}, func() {
+			t.drop(i)
+		})
+	}
+
+	return t
+}
+
+// The first error from this tee, or any of its nested ones.
+func (t *teeState) error() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil {
+		return t.err
+	}
+	for _, n := range t.nested {
+		if err := n.error(); err != nil {
+			return err
+		}
+	}
+	return t.nestedErr
+}
+
+// Whether every stream is released or finished.
+func (t *teeState) over() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, gone := range t.gone {
+		if !gone {
+			return false
+		}
+	}
+	return true
+}
+
+// Tees a nested stream. Streams that are already gone get released from it
+// straight away, so it doesn't wait on them. Must be called with the lock
+// held.
+func (t *teeState) teeNested(vs ValueStream) teedStream {
+	// forget about nested tees that are over, so they don't pile up
+	kept := t.nested[:0]
+	for _, n := range t.nested {
+		if !n.over() {
+			kept = append(kept, n)
+		} else if err := n.error(); err != nil && t.nestedErr == nil {
+			t.nestedErr = err
+		}
+	}
+	for k := len(kept); k < len(t.nested); k++ {
+		t.nested[k] = nil
+	}
+	t.nested = kept
+
+	n := newTee(vs, len(t.pos), t.maxLag, t.policy, func() {})
+	t.nested = append(t.nested, n)
+	for k, gone := range t.gone {
+		if gone {
+			n.streams[k].Release()
+		}
+	}
+	return teedStream(n.streams)
+}
+
+func (t *teeState) next(i int) (interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for {
+		if t.gone[i] {
+			return nil, true
+		}
+
+		if t.pos[i] < t.base+len(t.held) {
+			// there's a buffered value this stream hasn't had yet
+			value := t.held[t.pos[i]-t.base]
+			t.pos[i]++
+			t.trim()
+
+			if ts, ok := value.(teedStream); ok {
+				return ts[i].ValueStream, false
 			}
+			return value, false
+		}
 
-			if !done {
-				f1i++
+		if t.srcDone {
+			t.gone[i] = true
+			t.trim()
+			return nil, true
+		}
+
+		if t.pulling {
+			// another stream is fetching the next value; wait for it
+			t.cond.Wait()
+			continue
+		}
+
+		if t.maxLag > 0 && t.pos[i]-t.slowest() >= t.maxLag {
+			switch t.policy {
+			case LagBlock:
+				t.cond.Wait()
+				continue
+			case LagError:
+				t.err = ErrLagExceeded
+				t.gone[i] = true
+				t.trim()
+				return nil, true
 			}
-			return
-		}, func() (value interface{}, done bool) {
-			if f2i >= f1i {
-				// this stream is ahead, pull from the source
-				value, done = src()
-				if !done {
-					// recursively dup streams
-					if vs, ok := value.(ValueStream); ok {
-						vs, value = vs.Split()
-						held = append(held, vs)
-					} else {
-						held = append(held, value)
+			// LagDrop waits until there's actually a new value to make room for
+		}
+
+		// this stream is furthest ahead; pull the next value from the source.
+		// don't hold the lock while doing it, the source may well block.
+		t.pulling = true
+		t.mu.Unlock()
+		value, done := t.src()
+		t.mu.Lock()
+		t.pulling = false
+
+		if done {
+			t.srcDone = true
+		} else if vs, ok := value.(ValueStream); ok {
+			// recursively tee streams
+			t.held = append(t.held, t.teeNested(vs))
+		} else {
+			t.held = append(t.held, value)
+		}
+
+		if t.policy == LagDrop && t.maxLag > 0 {
+			for t.base+len(t.held)-t.slowest() > t.maxLag {
+				// push the slowest streams past the oldest value
+				t.held[0] = nil
+				t.held = t.held[1:]
+				t.base++
+				for k := range t.pos {
+					if t.pos[k] < t.base {
+						t.pos[k] = t.base
 					}
 				}
-			} else {
-				value, held = held[0], held[1:]
 			}
+		}
+		t.cond.Broadcast()
+	}
+}
 
-			if !done {
-				f2i++
-			}
-			return
+// Position of the slowest stream still in play.
+func (t *teeState) slowest() int {
+	min := -1
+	for k, p := range t.pos {
+		if !t.gone[k] && (min == -1 || p < min) {
+			min = p
 		}
+	}
+
+	if min == -1 {
+		// nobody's left; nothing needs holding
+		return t.base + len(t.held)
+	}
+	return min
 }
 
+// Discards buffered values that every stream still in play has received.
+func (t *teeState) trim() {
+	for min := t.slowest(); t.base < min; t.base++ {
+		t.held[0] = nil // don't hang on to it in the backing array
+		t.held = t.held[1:]
+	}
+	t.cond.Broadcast()
+}
+
+func (t *teeState) drop(i int) {
+	t.mu.Lock()
+	// done with the nested streams it got from here, too
+	for _, n := range t.nested {
+		n.streams[i].Release()
+	}
+	t.gone[i] = true
+	t.trim()
+	t.live--
+	last := t.live == 0
+	t.mu.Unlock()
+
+	if last {
+		t.release()
+	}
+}
+
+// Sends every value from the stream into the channel, then closes it.
+//
+// If whatever is receiving from the channel stops early, this will block
+// forever. Use ChanStream if the consumer might not drain everything.
 func StreamIntoChan(vs ValueStream, c chan<- interface{}) {
 	vs.Each(func(v interface{}) {
 		c <- v
@@ -155,39 +408,151 @@ func StreamIntoChan(vs ValueStream, c chan<- interface{}) {
 // original source stream. Once you call this, if you consume from the original
 // source stream again, that value will be lost to the flattener.
 func (vs ValueStream) Flatten() ValueStream {
-	// create stack of streams and push the first one on
-	var ss []ValueStream
-	ss = append(ss, vs)
-
-	var f ValueStream
+	f, _ := flatten(vs)
+	return f
+}
 
-	f = func() (value interface{}, done bool) {
-		size := len(ss)
-		if size == 0 {
-			// no streams left, we're definitely done
-			return nil, true
+// Does the work for both flavors of Flatten. The returned func releases
+// any releasable streams that the flattener is still partway through.
+func flatten(vs ValueStream) (ValueStream, func()) {
+	// create stack of streams and push the first one on. keep a parallel
+	// stack of releasers; entries are nil for streams that can't be released.
+	ss := []ValueStream{vs}
+	rs := []Releaser{nil}
+
+	pop := func() {
+		if r := rs[len(rs)-1]; r != nil {
+			// this one's exhausted, so it's done with either way
+			r.Release()
 		}
+		ss, rs = ss[:len(ss)-1], rs[:len(rs)-1]
+	}
 
-		// grab value from stream on top of stack
-		value, done = ss[size-1]()
+	f := func() (value interface{}, done bool) {
+		for len(ss) != 0 {
+			// grab value from stream on top of stack
+			value, done = ss[len(ss)-1]()
 
-		if done {
-			// this stream is done; pop the stack and recurse
-			ss = ss[:size-1]
-			return f()
-		}
+			if done {
+				// this stream is done; pop the stack and go again
+				pop()
+				continue
+			}
 
-		if innerstream, ok := value.(ValueStream); ok {
-			// we got another stream, push it on the stack and recurse
-			ss = append(ss, innerstream)
-			return f()
+			switch inner := value.(type) {
+			case ValueStream:
+				// we got another stream, push it on the stack and go again
+				ss, rs = append(ss, inner), append(rs, nil)
+				continue
+			case *ReleasableStream:
+				ss, rs = append(ss, inner.ValueStream), append(rs, inner)
+				continue
+			}
+
+			// most basic case - we found a leaf. return it.
+			return value, false
 		}
 
-		// most basic case - we found a leaf. return it.
-		return
+		// no streams left, we're definitely done
+		return nil, true
 	}
 
-	return f
+	return f, func() {
+		for len(ss) != 0 {
+			pop()
+		}
+	}
+}
+
+// Releaser is implemented by stream sources that hold on to something - a
+// goroutine, a file handle - which should be freed once the consumer no longer
+// needs any more values.
+//
+// Processors check their input collection for this interface, and call
+// Release once they've stopped pulling from it - whether because the input
+// was exhausted, or because the pipeline terminated early.
+type Releaser interface {
+	Release()
+}
+
+// A ReleasableStream is a ValueStream paired with a func that tells its
+// producer to stop. It is Streamable, so it can be passed to processors (and
+// ToStream) anywhere a collection is accepted.
+//
+// Release may be called more than once, and from any goroutine; only the first
+// call does anything. Once released, a stream may still return values it had
+// already produced, but will soon report being exhausted.
+type ReleasableStream struct {
+	ValueStream
+	release func()
+	once    sync.Once
+	c       <-chan interface{} // set if the stream is backed by a channel
+}
+
+// Wraps a stream up with the func that should be called when it's no longer needed.
+func Releasable(vs ValueStream, release func()) *ReleasableStream {
+	return &ReleasableStream{ValueStream: vs, release: release}
+}
+
+func (s *ReleasableStream) AsStream() ValueStream {
+	return s.ValueStream
+}
+
+func (s *ReleasableStream) Release() {
+	s.once.Do(s.release)
+}
+
+// Splits the stream as ValueStream.Split does. The source is released only
+// once both of the split streams have been released.
+func (s *ReleasableStream) Split() (*ReleasableStream, *ReleasableStream) {
+	ss, _ := s.Tee(2, 0, LagBlock)
+	return ss[0], ss[1]
+}
+
+// Flattens the stream as ValueStream.Flatten does. Releasing the flattened
+// stream releases the source, as well as any releasable inner streams that
+// haven't yet been exhausted.
+func (s *ReleasableStream) Flatten() *ReleasableStream {
+	f, release := flatten(s.ValueStream)
+	return Releasable(f, func() {
+		release()
+		s.Release()
+	})
+}
+
+// ChanStream starts a goroutine that reads values from the collection and
+// sends them into a channel with the given buffer size, and returns a stream
+// that receives from that channel.
+//
+// This is the releasable version of StreamIntoChan. Releasing the returned
+// stream stops the goroutine; if the collection is itself releasable, it is
+// released once the goroutine is done with it.
+func ChanStream(coll interface{}, buf int) *ReleasableStream {
+	src, release := streamOf(coll)
+	c := make(chan interface{}, buf)
+	stop := make(chan struct{})
+
+	go func() {
+		defer close(c)
+		defer release()
+
+		for v, done := src(); !done; v, done = src() {
+			select {
+			case c <- v:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	rs := Releasable(func() (interface{}, bool) {
+		v, ok := <-c
+		return v, !ok
+	}, func() {
+		close(stop)
+	})
+	rs.c = c
+	return rs
 }
 
 // If something has a special way of representing itself a stream, it should
@@ -197,32 +562,131 @@ type Streamable interface {
 }
 
 // Bind a function to the given collection that will allow traversal for reducing
+//
+// Any slice, array, map, string, receivable channel, or standard library
+// iterator can be streamed, as can anything Streamable. Maps stream a Pair
+// for each entry, in no particular order. Strings stream their runes; to
+// stream the bytes instead, pass a []byte.
+//
+// Panics if the collection is of a type that can't be streamed; use
+// ToStreamErr to get an error instead.
 func ToStream(collection interface{}) ValueStream {
+	vs, err := ToStreamErr(collection)
+	if err != nil {
+		panic("not supported...yet")
+	}
+	return vs
+}
+
+// ToStreamErr is ToStream, but returns an error wrapping ErrNotSupported
+// rather than panicking if the collection can't be streamed.
+func ToStreamErr(collection interface{}) (ValueStream, error) {
 	// If the structure already provides a reducing method, just return that.
 	if c, ok := collection.(Streamable); ok {
-		return c.AsStream()
+		return c.AsStream(), nil
 	}
 
+	// Common types get handled directly; everything else needs reflection.
 	switch c := collection.(type) {
 	case []int:
-		return iteratorToValueStream(&intSliceIterator{slice: c})
+		return iteratorToValueStream(&intSliceIterator{slice: c}), nil
 	case []interface{}:
-		return valueSlice(c).AsStream()
+		return valueSlice(c).AsStream(), nil
 	case ValueStream:
-		return c
+		return c, nil
 	case <-chan interface{}:
-		return func() (value interface{}, done bool) {
-			value, done = <-c
-			return
-		}
+		return func() (interface{}, bool) {
+			value, ok := <-c
+			return value, !ok
+		}, nil
 	case chan interface{}:
-		return func() (value interface{}, done bool) {
-			value, done = <-c
-			return
+		return func() (interface{}, bool) {
+			value, ok := <-c
+			return value, !ok
+		}, nil
+	case []string:
+		var pos int
+		return func() (interface{}, bool) {
+			if pos >= len(c) {
+				return nil, true
+			}
+			pos++
+			return c[pos-1], false
+		}, nil
+	case []float64:
+		var pos int
+		return func() (interface{}, bool) {
+			if pos >= len(c) {
+				return nil, true
+			}
+			pos++
+			return c[pos-1], false
+		}, nil
+	case []byte:
+		var pos int
+		return func() (interface{}, bool) {
+			if pos >= len(c) {
+				return nil, true
+			}
+			pos++
+			return c[pos-1], false
+		}, nil
+	case string:
+		var pos int
+		return func() (interface{}, bool) {
+			if pos >= len(c) {
+				return nil, true
+			}
+			r, size := utf8.DecodeRuneInString(c[pos:])
+			pos += size
+			return r, false
+		}, nil
+	}
+
+	// Iterators get converted, but have no way of being stopped early here;
+	// if that's needed, use FromSeq/FromSeq2 (which processors do for you).
+	if rs, ok := seqStream(collection); ok {
+		return rs.ValueStream, nil
+	}
+
+	return reflectStream(collection)
+}
+
+// Streams the various kinds of collection via reflection.
+func reflectStream(collection interface{}) (ValueStream, error) {
+	rv := reflect.ValueOf(collection)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var pos int
+		return func() (interface{}, bool) {
+			if pos >= rv.Len() {
+				return nil, true
+			}
+			pos++
+			return rv.Index(pos - 1).Interface(), false
+		}, nil
+	case reflect.Map:
+		iter := rv.MapRange()
+		return func() (interface{}, bool) {
+			if !iter.Next() {
+				return nil, true
+			}
+			return Pair{iter.Key().Interface(), iter.Value().Interface()}, false
+		}, nil
+	case reflect.Chan:
+		if rv.Type().ChanDir()&reflect.RecvDir != 0 {
+			return func() (interface{}, bool) {
+				value, ok := rv.Recv()
+				if !ok {
+					return nil, true
+				}
+				return value.Interface(), false
+			}, nil
 		}
-	default:
-		panic("not supported...yet")
 	}
+
+	return nil, fmt.Errorf("cannot stream %T: %w", collection, ErrNotSupported)
 }
 
 // Wrap an iterator up into a ValueStream func.
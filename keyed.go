@@ -0,0 +1,222 @@
+package transducers
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// A StateStore holds the per-key state for Keyed. Each pipeline gets its own
+// store, so it's only ever used by one goroutine at a time.
+//
+// The state is opaque, and Keyed is in charge of its lifetime: a store has to
+// hand back whatever was last stored under a key until Keyed deletes it, and
+// must never drop state of its own accord. To have idle keys evicted, use a
+// TTL.
+//
+// A *sync.Map is a StateStore, as is the default map-backed one.
+type StateStore interface {
+	Load(key interface{}) (state interface{}, ok bool)
+	Store(key interface{}, state interface{})
+	Delete(key interface{})
+}
+
+type mapStore map[interface{}]interface{}
+
+func (s mapStore) Load(key interface{}) (interface{}, bool) {
+	state, ok := s[key]
+	return state, ok
+}
+
+func (s mapStore) Store(key interface{}, state interface{}) {
+	s[key] = state
+}
+
+func (s mapStore) Delete(key interface{}) {
+	delete(s, key)
+}
+
+// KeyedOptions tunes Keyed processing; see KeyedWith.
+type KeyedOptions struct {
+	// TTL is how long a key can go without a value before its state is
+	// evicted. Zero means keys are never evicted.
+	TTL time.Duration
+	// Clock tells the time for TTL. Defaults to SystemClock.
+	Clock Clock
+	// Store creates the StateStore for each pipeline. Defaults to an in-memory
+	// map.
+	Store func() StateStore
+}
+
+// A key's own pipeline, ending in the shared tail.
+type keyState struct {
+	k    interface{}
+	pipe Reducer
+	done bool // the key's pipeline terminated
+	seen time.Time
+	elem *list.Element
+}
+
+type keyed struct {
+	reducerBase
+	key     Mapper
+	factory func() Transducer
+	ttl     time.Duration
+	clock   Clock
+	store   StateStore
+	// every live key, least recently seen first
+	order *list.List
+
+	// guards the next reducer, which a key's pipeline may step from another
+	// goroutine (as WindowByTime does under Go)
+	mu        sync.Mutex
+	terminate bool
+}
+
+// The bottom of each key's pipeline. It steps the next reducer, but leaves
+// completing it to the keyed reducer.
+type keyedTail struct {
+	r *keyed
+}
+
+func (t keyedTail) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+
+	if t.r.terminate {
+		return accum, true
+	}
+
+	accum, t.r.terminate = t.r.next.Step(accum, value)
+	return accum, t.r.terminate
+}
+
+func (t keyedTail) Complete(accum interface{}) interface{} {
+	return accum
+}
+
+func (t keyedTail) Init() interface{} {
+	return t.r.next.Init()
+}
+
+func (r *keyed) terminated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.terminate
+}
+
+func (r *keyed) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	k, value := keyOf(r.key, value)
+
+	var now time.Time
+	if r.ttl > 0 {
+		now = r.clock.Now()
+		accum = r.evict(accum, now)
+		if r.terminated() {
+			return accum, true
+		}
+	}
+
+	var s *keyState
+	if state, has := r.store.Load(k); has {
+		s = state.(*keyState)
+		r.order.MoveToBack(s.elem)
+	} else {
+		s = &keyState{k: k, pipe: r.factory()(keyedTail{r})}
+		s.elem = r.order.PushBack(s)
+		r.store.Store(k, s)
+	}
+	s.seen = now
+
+	if !s.done {
+		accum, s.done = s.pipe.Step(accum, value)
+	}
+	return accum, r.terminated()
+}
+
+// Forgets a key's state, unless the store has since moved on to a new state
+// for the key.
+func (r *keyed) forget(s *keyState) {
+	if state, has := r.store.Load(s.k); has && state == s {
+		r.store.Delete(s.k)
+	}
+}
+
+// Completes and forgets every key that's been idle for the TTL.
+func (r *keyed) evict(accum interface{}, now time.Time) interface{} {
+	for e := r.order.Front(); e != nil; e = r.order.Front() {
+		s := e.Value.(*keyState)
+		if now.Sub(s.seen) < r.ttl {
+			break
+		}
+
+		r.order.Remove(e)
+		r.forget(s)
+		// flushes anything the key's pipeline was holding on to
+		accum = s.pipe.Complete(accum)
+	}
+	return accum
+}
+
+func (r *keyed) Complete(accum interface{}) interface{} {
+	for e := r.order.Front(); e != nil; e = e.Next() {
+		s := e.Value.(*keyState)
+		r.forget(s)
+		accum = s.pipe.Complete(accum)
+	}
+	r.order.Init()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.next.Complete(accum)
+}
+
+// Keyed runs values through a separate transducer for each key - as the given
+// func returns for each of them - so that stateful transducers apply to each
+// key on its own. For example, to chunk each user's events separately:
+//
+//	Keyed(userID, func() Transducer { return Chunk(10) })
+//
+// factory is called for each new key. The transducers it makes all pass their
+// values along to the same next reducer. When one of them terminates, later
+// values for its key are dropped; if the next reducer terminates, so does
+// Keyed.
+//
+// Keys needn't be comparable (they're handled as with Distinct). On Complete,
+// each key's transducer is completed, least recently seen first.
+func Keyed(key Mapper, factory func() Transducer) Transducer {
+	return KeyedWith(key, factory, KeyedOptions{})
+}
+
+// KeyedWith is Keyed, with options.
+//
+// If there's a TTL, a key's state is evicted once the key goes that long
+// without a value: its transducer is completed (so Chunk, say, passes along
+// what it was holding), and if the key turns up again, it starts over with a
+// new one. Evictions happen as values arrive, so a stream that goes quiet
+// holds on to its keys until the next value, or Complete.
+func KeyedWith(key Mapper, factory func() Transducer, opts KeyedOptions) Transducer {
+	if opts.TTL < 0 {
+		panic("TTL cannot be negative")
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock{}
+	}
+	if opts.Store == nil {
+		opts.Store = func() StateStore {
+			return make(mapStore)
+		}
+	}
+
+	return func(r Reducer) Reducer {
+		return &keyed{
+			reducerBase: reducerBase{r},
+			key:         key,
+			factory:     factory,
+			ttl:         opts.TTL,
+			clock:       opts.Clock,
+			store:       opts.Store(),
+			order:       list.New(),
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package transducers
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func pairKey(v interface{}) interface{} {
+	return v.(Pair).Key
+}
+
+func pairValue(v interface{}) interface{} {
+	return v.(Pair).Value
+}
+
+func pairs(kv ...interface{}) []interface{} {
+	var ps []interface{}
+	for k := 0; k < len(kv); k += 2 {
+		ps = append(ps, Pair{kv[k], kv[k+1]})
+	}
+	return ps
+}
+
+func TestKeyedDedupe(t *testing.T) {
+	src := pairs("a", 1, "b", 1, "a", 1, "b", 2, "a", 2, "b", 2, "a", 1)
+	result := Transduce(src, Append(), Keyed(pairKey, func() Transducer {
+		return Comp(Map(pairValue), Dedupe())
+	})).([]int)
+
+	// a: 1 1 2 1, b: 1 2 2
+	intSliceEquals([]int{1, 1, 2, 2, 1}, result, t)
+}
+
+func TestKeyedChunk(t *testing.T) {
+	src := pairs("a", 1, "b", 10, "a", 2, "c", 100, "a", 3, "b", 20, "a", 4, "b", 30, "b", 40)
+	var chunks []string
+	Transduce(src, CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		chunks = append(chunks, fmt.Sprint(ToSlice(value.(ValueStream))))
+		return accum, false
+	}), Keyed(pairKey, func() Transducer {
+		return Comp(Map(pairValue), Chunk(3))
+	}))
+
+	// the leftovers are flushed least recently seen first
+	expected := "[[1 2 3] [10 20 30] [100] [4] [40]]"
+	if fmt.Sprint(chunks) != expected {
+		t.Errorf("Expected chunks %v, got %v", expected, chunks)
+	}
+}
+
+func TestKeyedTermination(t *testing.T) {
+	src := pairs("a", 1, "b", 10, "a", 2, "a", 3, "b", 20, "b", 30, "c", 100)
+	per := func() Transducer {
+		return Comp(Map(pairValue), Take(2))
+	}
+
+	// each key stops on its own
+	result := Transduce(src, Append(), Keyed(pairKey, per)).([]int)
+	intSliceEquals([]int{1, 10, 2, 20, 100}, result, t)
+
+	// the whole thing stops when the next reducer does
+	result = Transduce(src, Append(), Keyed(pairKey, per), Take(3)).([]int)
+	intSliceEquals([]int{1, 10, 2}, result, t)
+}
+
+func TestKeyedTTL(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	src := pairs("a", 1, "b", 10, "a", 2, "b", 20, "a", 3, "a", 4, "b", 30)
+
+	result := Transduce(src, Append(),
+		// a minute passes before each of a's 3 and b's 30
+		Map(func(v interface{}) interface{} {
+			if p := v.(Pair); p.Value == 3 || p.Value == 30 {
+				clock.Advance(time.Minute)
+			}
+			return v
+		}),
+		KeyedWith(pairKey, func() Transducer {
+			return Comp(Map(pairValue), Chunk(3), Map(Sum))
+		}, KeyedOptions{TTL: time.Minute, Clock: clock}),
+	).([]int)
+
+	// both keys are idle at a's 3, so their chunks are flushed, and a starts
+	// over; then a is evicted at b's 30
+	intSliceEquals([]int{3, 30, 7, 30}, result, t)
+}
+
+func TestKeyedStore(t *testing.T) {
+	var stores []*sync.Map
+	td := KeyedWith(pairKey, func() Transducer {
+		return Comp(Map(pairValue), Dedupe())
+	}, KeyedOptions{Store: func() StateStore {
+		s := new(sync.Map)
+		stores = append(stores, s)
+		return s
+	}})
+
+	p := CreatePipeline(Append(), td)
+	acc := p.Init()
+	for _, v := range pairs("a", 1, "b", 1, "a", 1) {
+		acc, _ = p.Step(acc, v)
+	}
+
+	var keys int
+	stores[0].Range(func(k, v interface{}) bool {
+		keys++
+		return true
+	})
+	if keys != 2 {
+		t.Error("Expected the store to hold state for 2 keys, got", keys)
+	}
+
+	intSliceEquals([]int{1, 1}, p.Complete(acc).([]int), t)
+	if _, has := stores[0].Load("a"); has {
+		t.Error("Expected keys to be cleared from the store on Complete")
+	}
+}
+
+func TestKeyedWindowsGo(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	stop := make(chan struct{})
+
+	var i int
+	src := Releasable(func() (interface{}, bool) {
+		select {
+		case <-stop:
+			return nil, true
+		default:
+		}
+		i++
+		return Pair{i % 2, i}, false
+	}, func() {
+		close(stop)
+	})
+
+	// each key's timers step the rest of the pipeline from the clock's
+	// goroutine, while values for the other key are stepped through from Go's
+	out := Go(src, 0, Keyed(pairKey, func() Transducer {
+		return Comp(Map(pairValue), WindowByTime(time.Second, clock))
+	}), Take(20))
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				clock.Advance(time.Second)
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	defer close(done)
+
+	var windows int
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				if windows != 20 {
+					t.Error("Expected 20 windows, got", windows)
+				}
+				return
+			}
+			windows++
+		case <-timeout:
+			t.Fatal("Pipeline never terminated")
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package transducers
+
+type multi []Reducer
+
+// Accumulator for Multi.
+type multiAccum struct {
+	accs []interface{}
+	done []bool
+	live int // branches that haven't terminated
+}
+
+func (r multi) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	m := accum.(*multiAccum)
+
+	// each branch needs its own copy of a stream
+	var streams []*ReleasableStream
+	if vs, ok := value.(ValueStream); ok && len(r) > 1 {
+		streams, _ = vs.Tee(len(r), 0, LagBlock)
+	}
+
+	for k, br := range r {
+		if m.done[k] {
+			if streams != nil {
+				streams[k].Release()
+			}
+			continue
+		}
+
+		v := value
+		if streams != nil {
+			v = streams[k].ValueStream
+		}
+
+		m.accs[k], m.done[k] = br.Step(m.accs[k], v)
+		if m.done[k] {
+			m.live--
+		}
+	}
+
+	return m, m.live == 0
+}
+
+func (r multi) Complete(accum interface{}) interface{} {
+	m := accum.(*multiAccum)
+	results := make([]interface{}, len(r))
+	for k, br := range r {
+		results[k] = br.Complete(m.accs[k])
+	}
+	return results
+}
+
+func (r multi) Init() interface{} {
+	m := &multiAccum{
+		accs: make([]interface{}, len(r)),
+		done: make([]bool, len(r)),
+		live: len(r),
+	}
+	for k, br := range r {
+		m.accs[k] = br.Init()
+	}
+	return m
+}
+
+// Multi is a bottom reducer that feeds each value to all of the given
+// reducers at once, so several results can be had from a single pass over a
+// collection. On Complete, it returns a []interface{} of their results, in the
+// same order as the reducers.
+//
+// Once a reducer terminates, it gets no more values, but the others carry on.
+// Multi itself only terminates once all of them have.
+//
+// To put a transducer stack in front of just one of the reducers, wrap it up
+// with Branch.
+func Multi(reducers ...Reducer) Reducer {
+	return multi(reducers)
+}
+
+type branch struct {
+	r     Reducer
+	xform []Transducer
+}
+
+// Accumulator for Branch; a branch's pipeline is kept here, so that each
+// process gets its own.
+type branchAccum struct {
+	pipe Reducer
+	acc  interface{}
+}
+
+func (b branch) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	ba := accum.(*branchAccum)
+	var terminate bool
+	ba.acc, terminate = ba.pipe.Step(ba.acc, value)
+	return ba, terminate
+}
+
+func (b branch) Complete(accum interface{}) interface{} {
+	ba := accum.(*branchAccum)
+	return ba.pipe.Complete(ba.acc)
+}
+
+func (b branch) Init() interface{} {
+	pipe := CreatePipeline(b.r, b.xform...)
+	return &branchAccum{pipe, pipe.Init()}
+}
+
+// Branch turns a bottom reducer and a stack of transducers into a new bottom
+// reducer, which runs values through the stack on their way to the original.
+// A new pipeline is created each time Init is called.
+//
+// It's meant for Multi, but works anywhere a bottom reducer does.
+func Branch(r Reducer, xform ...Transducer) Reducer {
+	return branch{r, xform}
+}
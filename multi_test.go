@@ -0,0 +1,49 @@
+package transducers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	m := Multi(
+		Count(),
+		Total(),
+		Branch(tb(), Filter(Even), Take(3)),
+	)
+
+	result := Transduce(Range(10), m).([]interface{})
+	if fmt.Sprint(result) != "[10 45 [0 2 4]]" {
+		t.Error("Unexpected results", result)
+	}
+
+	// again, to be sure the Take in the branch gets fresh state
+	result = Transduce(Range(10), m).([]interface{})
+	if fmt.Sprint(result) != "[10 45 [0 2 4]]" {
+		t.Error("Unexpected results", result)
+	}
+}
+
+func TestMultiTerminates(t *testing.T) {
+	var stepped int
+	counter := Map(func(v interface{}) interface{} {
+		stepped++
+		return v
+	})
+
+	m := Multi(Branch(tb(), Take(2)), Branch(tb(), Filter(Even), Take(3)))
+	result := Transduce(naturals(), m, counter).([]interface{})
+	if fmt.Sprint(result) != "[[0 1] [0 2 4]]" {
+		t.Error("Unexpected results", result)
+	}
+	if stepped != 5 {
+		t.Error("Expected transduction to stop once both branches had, but it stepped", stepped, "values")
+	}
+}
+
+func TestMultiStreams(t *testing.T) {
+	result := Transduce(Range(5), Multi(Count(), Total(), IntoSliceOf[int]()), Chunk(2)).([]interface{})
+	if fmt.Sprint(result) != "[5 10 [0 1 2 3 4]]" {
+		t.Error("Each reducer should get the whole of each stream, got", result)
+	}
+}
@@ -0,0 +1,289 @@
+package transducers
+
+import "sync"
+
+// Stateless is implemented by reducers that keep no state from one step to
+// the next, and don't depend on the order in which values arrive - so a single
+// pipeline made from them can safely be stepped from many goroutines at once.
+//
+// Returning false lets a type opt back out, if it embeds one that opted in.
+type Stateless interface {
+	Stateless() bool
+}
+
+// IsStateless reports whether the reducers created by a transducer are
+// Stateless. Transducers that don't say either way are assumed not to be.
+func IsStateless(td Transducer) bool {
+	s, ok := td(CreateStep(nil)).(Stateless)
+	return ok && s.Stateless()
+}
+
+func (r map_r) Stateless() bool   { return true }
+func (r filter) Stateless() bool  { return true }
+func (r mapcat) Stateless() bool  { return true }
+func (r keep) Stateless() bool    { return true }
+func (r replace) Stateless() bool { return true }
+
+// TakeNth and RandomSample are built on filter, but count, or have their own
+// random source.
+func (r takeNth) Stateless() bool      { return false }
+func (r randomSample) Stateless() bool { return false }
+
+// Parallel runs a stack of stateless transducers (e.g. Map, Filter, Mapcat)
+// over up to the given number of values at once, each in its own goroutine.
+// Results are passed along in the same order as the values they came from, so
+// it can sit in front of stateful stages such as Chunk, Dedupe or Take.
+//
+// It's worth it when the stack does something expensive per value - I/O, in
+// particular. Panics if any of the transducers aren't Stateless.
+//
+// Values queue up inside Parallel while they're being worked on, and are
+// flushed out on Complete. If the pipeline terminates, whatever is still in
+// flight is discarded. If the stack panics on a value, the panic is re-raised
+// on the pipeline's goroutine when that value's results are due.
+//
+// Under Go, nothing has to wait for the next value: the workers pass their
+// results along themselves, as soon as they're due. A panic stops the source,
+// and is re-raised when the pipeline completes.
+func Parallel(workers int, tds ...Transducer) Transducer {
+	return parallel(workers, true, tds)
+}
+
+// ParallelUnordered is Parallel, but passes results along as soon as they're
+// ready, regardless of the order of the values they came from. This keeps one
+// slow value from holding up everything behind it.
+func ParallelUnordered(workers int, tds ...Transducer) Transducer {
+	return parallel(workers, false, tds)
+}
+
+// ParallelMap is Parallel, with just a Map in the stack.
+func ParallelMap(workers int, f Mapper) Transducer {
+	return Parallel(workers, Map(f))
+}
+
+// ParallelMapUnordered is ParallelUnordered, with just a Map in the stack.
+func ParallelMapUnordered(workers int, f Mapper) Transducer {
+	return ParallelUnordered(workers, Map(f))
+}
+
+func parallel(workers int, ordered bool, tds []Transducer) Transducer {
+	if workers < 1 {
+		panic("must have at least one worker")
+	}
+	for _, td := range tds {
+		if !IsStateless(td) {
+			panic("parallel stages must be stateless")
+		}
+	}
+
+	// the stack is stateless, so one pipeline can serve all the goroutines
+	inner := CreatePipeline(CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		return append(accum.([]interface{}), value), false
+	}), tds...)
+
+	return func(r Reducer) Reducer {
+		p := &parallelR{
+			reducerBase: reducerBase{r},
+			inner:       inner,
+			workers:     workers,
+			ordered:     ordered,
+		}
+		if !ordered {
+			// big enough that no worker ever blocks sending its results
+			p.results = make(chan parallelResult, workers)
+		}
+		return p
+	}
+}
+
+type parallelR struct {
+	reducerBase
+	inner     Reducer
+	workers   int
+	ordered   bool
+	terminate bool
+
+	// when ordered, one chan per value in flight, in input order
+	pending []chan parallelResult
+	// when unordered, the one chan everything comes back on
+	results  chan parallelResult
+	inflight int
+
+	// the Go processor's accumulator, if that's what's running the pipeline,
+	// in which case the workers step the next reducer, one at a time
+	detached *detached
+	mu       sync.Mutex
+	cond     *sync.Cond
+	panicked interface{}
+}
+
+// What a worker sends back: the values its stack produced, or what it
+// panicked with, to be re-panicked on the pipeline's own goroutine.
+type parallelResult struct {
+	values   []interface{}
+	panicked interface{}
+}
+
+func (r *parallelR) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	if d, ok := accum.(detached); ok {
+		return accum, r.stepDetached(d, value)
+	}
+
+	if r.inflight == r.workers {
+		// full up; wait for the next result before starting another
+		accum = r.emit(accum, true)
+		if r.terminate {
+			return accum, true
+		}
+	}
+
+	r.start(value)
+	// pass along anything that's already finished
+	accum = r.drain(accum)
+	return accum, r.terminate
+}
+
+func (r *parallelR) stepDetached(d detached, value interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.detached == nil {
+		r.detached = &d
+		r.cond = sync.NewCond(&r.mu)
+	}
+
+	// full up; wait for a worker to pass its results along
+	for r.inflight == r.workers && !r.over() {
+		r.cond.Wait()
+	}
+	if r.panicked != nil {
+		panic(r.panicked)
+	}
+	if r.terminate {
+		return true
+	}
+
+	r.start(value)
+	return false
+}
+
+// Hands a value off to a worker.
+func (r *parallelR) start(value interface{}) {
+	c := r.results
+	if r.ordered {
+		c = make(chan parallelResult, 1)
+		r.pending = append(r.pending, c)
+	}
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				c <- parallelResult{panicked: p}
+			}
+			if r.detached != nil {
+				r.forward()
+			}
+		}()
+
+		ret, _ := r.inner.Step(make([]interface{}, 0), value)
+		c <- parallelResult{values: ret.([]interface{})}
+	}()
+	r.inflight++
+}
+
+// Passes along all the results that are ready, in order if need be.
+func (r *parallelR) drain(accum interface{}) interface{} {
+	for r.inflight != 0 && !r.over() {
+		before := r.inflight
+		accum = r.emit(accum, false)
+		if r.inflight == before {
+			break
+		}
+	}
+	return accum
+}
+
+// Called by each worker under Go, once it's sent its results. The results
+// might not be due yet, but then the worker holding things up will be along
+// to pass them on.
+func (r *parallelR) forward() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.over() {
+		return
+	}
+	r.drain(*r.detached)
+	if r.over() {
+		// no more input is needed, but the processor may be stuck waiting for it
+		r.detached.stop()
+	}
+	r.cond.Broadcast()
+}
+
+func (r *parallelR) over() bool {
+	return r.terminate || r.panicked != nil
+}
+
+// Passes the next available results down the pipeline. If wait is false and
+// nothing is ready, does nothing.
+func (r *parallelR) emit(accum interface{}, wait bool) interface{} {
+	c := r.results
+	if r.ordered {
+		c = r.pending[0]
+	}
+
+	var results parallelResult
+	if wait {
+		results = <-c
+	} else {
+		select {
+		case results = <-c:
+		default:
+			return accum
+		}
+	}
+
+	if r.ordered {
+		r.pending = r.pending[1:]
+	}
+	r.inflight--
+
+	if results.panicked != nil {
+		if r.detached != nil {
+			// can't panic on a worker's goroutine
+			r.panicked = results.panicked
+			return accum
+		}
+		panic(results.panicked)
+	}
+	for _, v := range results.values {
+		accum, r.terminate = r.next.Step(accum, v)
+		if r.terminate {
+			break
+		}
+	}
+	return accum
+}
+
+func (r *parallelR) Complete(accum interface{}) interface{} {
+	if r.detached != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		for r.inflight != 0 && !r.over() {
+			r.cond.Wait()
+		}
+		if r.panicked != nil {
+			panic(r.panicked)
+		}
+		return r.next.Complete(accum)
+	}
+
+	// every chan is buffered, so abandoned workers won't leak if we terminated
+	for r.inflight != 0 && !r.terminate {
+		accum = r.emit(accum, true)
+	}
+
+	return r.next.Complete(accum)
+}
@@ -0,0 +1,156 @@
+package transducers
+
+import (
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A mapper that takes longer for smaller values, and records how many calls
+// were running at once.
+func slowInc(running, peak *int32) Mapper {
+	return func(value interface{}) interface{} {
+		n := atomic.AddInt32(running, 1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Duration(10-value.(int)) * time.Millisecond)
+		atomic.AddInt32(running, -1)
+		return value.(int) + 1
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	var running, peak int32
+	result := Transduce(Range(10), tb(), ParallelMap(4, slowInc(&running, &peak))).([]int)
+	intSliceEquals([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, result, t)
+
+	if peak < 2 || peak > 4 {
+		t.Error("Expected between 2 and 4 concurrent calls, got", peak)
+	}
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	var running, peak int32
+	result := Transduce(Range(10), tb(), ParallelMapUnordered(3, slowInc(&running, &peak))).([]int)
+
+	sort.Ints(result)
+	intSliceEquals([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, result, t)
+	if peak > 3 {
+		t.Error("Expected at most 3 concurrent calls, got", peak)
+	}
+}
+
+func TestParallelStack(t *testing.T) {
+	xf := []Transducer{Parallel(3, Map(Inc), Filter(Even), Mapcat(Range)), Chunk(3), Take(2), Mapcat(Flatten)}
+
+	result := Transduce(Range(6), tb(), xf...).([]int)
+	intSliceEquals([]int{0, 1, 0, 1, 2, 3}, result, t)
+
+	// works the same through the other processors
+	streamEquals(toi(0, 1, 0, 1, 2, 3), Eduction(Range(6), xf...), t)
+	chanEquals(toi(0, 1, 0, 1, 2, 3), Go(rchan(6), 0, xf...), t)
+}
+
+func TestParallelGoIdle(t *testing.T) {
+	for name, td := range map[string]Transducer{"Parallel": ParallelMap(2, Inc), "ParallelUnordered": ParallelMapUnordered(2, Inc)} {
+		in := make(chan interface{})
+		out := Go(in, 0, td)
+
+		// the result comes out while the input has nothing more to give
+		in <- 1
+		select {
+		case v := <-out:
+			if v != 2 {
+				t.Errorf("%s: expected 2, got %v", name, v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: result was held up waiting for more input", name)
+		}
+
+		close(in)
+		chanEquals(nil, out, t)
+	}
+}
+
+func TestParallelGoOrder(t *testing.T) {
+	// later values finish first, but still come out in order
+	slow := func(v interface{}) interface{} {
+		time.Sleep(time.Duration(10-v.(int)) * time.Millisecond)
+		return v
+	}
+	chanEquals(toi(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), Go(rchan(10), 0, ParallelMap(4, slow), Take(10)), t)
+	chanEquals(toi(0, 1, 2), Go(rchan(10), 0, ParallelMap(4, slow), Take(3)), t)
+}
+
+func TestParallelPanics(t *testing.T) {
+	boom := func(v interface{}) interface{} {
+		if v.(int) == 3 {
+			panic("boom")
+		}
+		return v
+	}
+
+	for name, td := range map[string]Transducer{"Parallel": ParallelMap(2, boom), "ParallelUnordered": ParallelMapUnordered(2, boom)} {
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Errorf("%s: expected the worker's panic to be re-raised, got %v", name, p)
+				}
+			}()
+			Transduce(Range(6), tb(), td)
+		}()
+	}
+}
+
+func TestParallelRejectsStateful(t *testing.T) {
+	for name, td := range map[string]Transducer{"Take": Take(2), "TakeNth": TakeNth(2), "Dedupe": Dedupe(), "RandomSample": RandomSample(0.5)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Parallel accepted a stateful", name)
+				}
+			}()
+			Parallel(2, Map(Inc), td)
+		}()
+	}
+}
+
+func concatInts(left, right interface{}) interface{} {
+	return append(left.([]int), right.([]int)...)
+}
+
+func TestFold(t *testing.T) {
+	xf := []Transducer{Map(Inc), Filter(Even), Mapcat(Range)}
+	expected := Transduce(t_range(100), tb(), xf...).([]int)
+
+	var combines int
+	combine := func(left, right interface{}) interface{} {
+		combines++
+		return concatInts(left, right)
+	}
+
+	result := Fold(t_range(100), 7, combine, tb(), xf...).([]int)
+	intSliceEquals(expected, result, t)
+	if combines != 14 {
+		t.Error("Expected 15 segments to be combined 14 times, got", combines)
+	}
+
+	result = Fold(ToSlice(Range(100)), 30, concatInts, tb(), xf...).([]int)
+	intSliceEquals(expected, result, t)
+}
+
+func TestFoldSerialisesStateful(t *testing.T) {
+	// Take(5) on each segment would produce 5 values per segment
+	xf := []Transducer{Map(Inc), Take(5)}
+	result := Fold(t_range(100), 10, func(left, right interface{}) interface{} {
+		t.Error("Fold should not have split up a stateful stack")
+		return left
+	}, tb(), xf...).([]int)
+	intSliceEquals([]int{1, 2, 3, 4, 5}, result, t)
+}
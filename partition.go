@@ -0,0 +1,121 @@
+package transducers
+
+type partition struct {
+	reducerBase
+	n, step   int
+	pad       interface{} // collection to pad with, if padding
+	padded    bool
+	all       bool
+	window    valueSlice
+	skip      int // values to drop before the next window starts
+	terminate bool
+}
+
+func (t *partition) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	if t.skip > 0 {
+		t.skip--
+		return accum, false
+	}
+
+	t.window = append(t.window, value)
+	if len(t.window) < t.n {
+		return accum, false
+	}
+
+	full := t.window
+	if t.step < t.n {
+		// windows overlap, so the next one starts with the tail of this one
+		t.window = append(make(valueSlice, 0, t.n), full[t.step:]...)
+	} else {
+		t.window = make(valueSlice, 0, t.n)
+		t.skip = t.step - t.n
+	}
+
+	accum, t.terminate = t.next.Step(accum, full.AsStream())
+	return accum, t.terminate
+}
+
+func (t *partition) Complete(accum interface{}) interface{} {
+	switch {
+	case t.terminate:
+	case t.all:
+		// every window that started gets sent, however short
+		for len(t.window) > 0 && !t.terminate {
+			w := t.window
+			if t.step < len(w) {
+				t.window = w[t.step:]
+			} else {
+				t.window = nil
+			}
+			accum, t.terminate = t.next.Step(accum, w[:len(w):len(w)].AsStream())
+		}
+	case t.padded && len(t.window) > 0:
+		// only the first short window gets padded; it may still come up
+		// short, if there's not enough padding
+		if t.pad != nil {
+			pad := ToStream(t.pad)
+			for v, done := pad(); !done && len(t.window) < t.n; v, done = pad() {
+				t.window = append(t.window, v)
+			}
+		}
+		accum, t.terminate = t.next.Step(accum, t.window.AsStream())
+	}
+
+	return t.next.Complete(accum)
+}
+
+func newPartition(n, step int) *partition {
+	if n < 1 {
+		panic("partitions must be at least one element in size")
+	}
+	if step < 1 {
+		panic("partitions must step forward at least one element")
+	}
+
+	return &partition{n: n, step: step, window: make(valueSlice, 0, n)}
+}
+
+// Partition groups values into windows of n values, each starting step values
+// after the last one started, and passes them along as ValueStreams, just
+// like Clojure's partition.
+//
+// If step is less than n, the windows overlap (sliding windows, as for moving
+// averages or n-grams); if it's equal, they're just like Chunk's; if it's
+// greater, values in between windows are skipped. Windows with fewer than n
+// values at the end of the input are dropped - see PartitionPad and
+// PartitionAll for ways of keeping them.
+func Partition(n, step int) Transducer {
+	newPartition(n, step) // check args up front
+	return func(r Reducer) Reducer {
+		t := newPartition(n, step)
+		t.next = r
+		return t
+	}
+}
+
+// PartitionPad is Partition, but if there's a window left short at the end
+// of the input, it's filled up with values from the pad collection (anything
+// ToStream accepts) and passed along. If there aren't enough pad values, the
+// window is passed along short.
+//
+// The pad collection is streamed from each time a pipeline completes, so it
+// shouldn't be a ValueStream if the transducer is used more than once.
+func PartitionPad(n, step int, pad interface{}) Transducer {
+	newPartition(n, step)
+	return func(r Reducer) Reducer {
+		t := newPartition(n, step)
+		t.next, t.pad, t.padded = r, pad, true
+		return t
+	}
+}
+
+// PartitionAll is Partition, but passes along all the windows left short at
+// the end of the input too, like Clojure's partition-all.
+func PartitionAll(n, step int) Transducer {
+	newPartition(n, step)
+	return func(r Reducer) Reducer {
+		t := newPartition(n, step)
+		t.next, t.all = r, true
+		return t
+	}
+}
@@ -0,0 +1,61 @@
+package transducers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func partitions(coll interface{}, td Transducer) string {
+	return fmt.Sprint(ToSlice(Eduction(coll, td)))
+}
+
+func TestPartition(t *testing.T) {
+	cases := []struct {
+		td       Transducer
+		expected string
+	}{
+		{Partition(3, 1), "[[0 1 2] [1 2 3] [2 3 4] [3 4 5] [4 5 6]]"},
+		{Partition(3, 3), "[[0 1 2] [3 4 5]]"},
+		{Partition(2, 3), "[[0 1] [3 4]]"},
+		{Partition(8, 1), "[]"},
+		{PartitionPad(3, 3, []int{-1, -2}), "[[0 1 2] [3 4 5] [6 -1 -2]]"},
+		{PartitionPad(3, 2, []int{-1}), "[[0 1 2] [2 3 4] [4 5 6] [6 -1]]"},
+		{PartitionPad(4, 4, []int{-1}), "[[0 1 2 3] [4 5 6 -1]]"},
+		{PartitionPad(3, 4, nil), "[[0 1 2] [4 5 6]]"},
+		{PartitionPad(2, 3, []int{-1}), "[[0 1] [3 4] [6 -1]]"},
+		{PartitionAll(3, 1), "[[0 1 2] [1 2 3] [2 3 4] [3 4 5] [4 5 6] [5 6] [6]]"},
+		{PartitionAll(3, 2), "[[0 1 2] [2 3 4] [4 5 6] [6]]"},
+		{PartitionAll(2, 3), "[[0 1] [3 4] [6]]"},
+	}
+
+	for k, c := range cases {
+		if result := partitions(Range(7), c.td); result != c.expected {
+			t.Errorf("Case %v: expected %v, got %v", k, c.expected, result)
+		}
+		// and again, to be sure state isn't shared between pipelines
+		if result := partitions(Range(7), c.td); result != c.expected {
+			t.Errorf("Case %v (second run): expected %v, got %v", k, c.expected, result)
+		}
+	}
+}
+
+func TestPartitionMovingAverage(t *testing.T) {
+	avg := func(v interface{}) interface{} {
+		vs := v.(ValueStream)
+		var sum, n int
+		vs.Each(func(v interface{}) {
+			sum += v.(int)
+			n++
+		})
+		return sum / n
+	}
+
+	result := Transduce([]int{2, 4, 6, 8, 10, 12}, tb(), Partition(3, 1), Map(avg)).([]int)
+	intSliceEquals([]int{4, 6, 8, 10}, result, t)
+}
+
+func TestPartitionTerminates(t *testing.T) {
+	if result := partitions(naturals(), Comp(PartitionAll(3, 1), Take(2))); result != "[[0 1 2] [1 2 3]]" {
+		t.Error("Unexpected result", result)
+	}
+}
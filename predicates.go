@@ -18,6 +18,16 @@ type Filterer func(interface{}) bool
 // a stream of values. Used by Mapcat.
 type Exploder func(interface{}) ValueStream
 
+// Combines the results of reducing two adjacent parts of a collection, left
+// before right, into one. Used by Fold.
+type Combiner func(left, right interface{}) interface{}
+
+// Transducer predicate function; used by MapErr. Same as Mapper, but can fail.
+type ErrMapper func(value interface{}) (interface{}, error)
+
+// Transducer predicate function; used by FilterErr. Same as Filterer, but can fail.
+type ErrFilterer func(interface{}) (bool, error)
+
 func sum(vs ValueStream) (total int) {
 	vs.Each(func(value interface{}) {
 		total += value.(int)
@@ -1,11 +1,59 @@
 package transducers
 
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// streamOf converts a processor's input collection to a stream, along with a
+// func to call once the processor is no longer pulling from it. If the
+// collection isn't a Releaser (or an iterator), that func does nothing.
+func streamOf(coll interface{}) (ValueStream, func()) {
+	vs, release, err := streamOfErr(coll)
+	if err != nil {
+		panic("not supported...yet")
+	}
+	return vs, release
+}
+
+func streamOfErr(coll interface{}) (ValueStream, func(), error) {
+	if rs, ok := seqStream(coll); ok {
+		// iterators have to be stopped if they aren't run to the end
+		return rs.ValueStream, rs.Release, nil
+	}
+
+	vs, err := ToStreamErr(coll)
+	return vs, releaserOf(coll), err
+}
+
+func releaserOf(coll interface{}) func() {
+	if r, ok := coll.(Releaser); ok {
+		return r.Release
+	}
+	return func() {}
+}
+
 // Transduce performs a non-lazy traversal/reduction over the provided value stream.
+//
+// If the collection is a Releaser, it is released as soon as transduction
+// stops pulling from it, before Complete is called.
 func Transduce(coll interface{}, bottom Reducer, tlist ...Transducer) interface{} {
-	// Final reducing func - append to slice
+	ret, _ := TransduceContext(context.Background(), coll, bottom, tlist...)
+	return ret
+}
+
+// TransduceContext is Transduce, but stops stepping values through the
+// pipeline as soon as the context is cancelled or its deadline passes.
+//
+// Complete is always called exactly once, whether or not the context was
+// cancelled, so stateful transducers still get to flush. The completed
+// (partial) result is returned along with ctx.Err() if transduction was cut
+// short by the context, or nil if it wasn't.
+func TransduceContext(ctx context.Context, coll interface{}, bottom Reducer, tlist ...Transducer) (interface{}, error) {
 	t := CreatePipeline(bottom, tlist...)
 
-	vs := ToStream(coll)
+	vs, release, err := contextStream(ctx, coll)
 	var ret interface{} = t.Init()
 	var terminate bool
 
@@ -16,8 +64,70 @@ func Transduce(coll interface{}, bottom Reducer, tlist ...Transducer) interface{
 		}
 	}
 
+	release()
 	ret = t.Complete(ret)
 
+	return ret, err()
+}
+
+// Fold is a parallel Transduce, along the lines of Clojure's r/fold.
+//
+// The collection is split into segments of about n values, and each one is
+// transduced in its own goroutine, through its own pipeline. The results are
+// then merged, in order, with the combine func - so for a collection split
+// into three segments, it returns combine(combine(r1, r2), r3).
+//
+// Only []int and []interface{} can be split up. Any other collection, or any
+// stack containing a transducer that isn't Stateless (because a Take or Chunk
+// running on each segment would give a different answer), is transduced
+// sequentially, as if by Transduce. The bottom reducer is shared by all the
+// goroutines, so it needs to keep its state in the accumulator.
+func Fold(coll interface{}, n int, combine Combiner, bottom Reducer, tlist ...Transducer) interface{} {
+	if n < 1 {
+		panic("segments must be at least one element in size")
+	}
+
+	var segments []interface{}
+	switch c := coll.(type) {
+	case []int:
+		for len(c) > n {
+			segments, c = append(segments, c[:n]), c[n:]
+		}
+		segments = append(segments, c)
+	case []interface{}:
+		for len(c) > n {
+			segments, c = append(segments, c[:n]), c[n:]
+		}
+		segments = append(segments, c)
+	}
+
+	for _, td := range tlist {
+		if !IsStateless(td) {
+			segments = nil
+			break
+		}
+	}
+
+	if len(segments) < 2 {
+		return Transduce(coll, bottom, tlist...)
+	}
+
+	results := make([]interface{}, len(segments))
+	var wg sync.WaitGroup
+	for k, seg := range segments {
+		wg.Add(1)
+		go func(k int, seg interface{}) {
+			defer wg.Done()
+			results[k] = Transduce(seg, bottom, tlist...)
+		}(k, seg)
+	}
+	wg.Wait()
+
+	ret := results[0]
+	for _, r := range results[1:] {
+		ret = combine(ret, r)
+	}
+
 	return ret
 }
 
@@ -42,17 +152,49 @@ func Transduce(coll interface{}, bottom Reducer, tlist ...Transducer) interface{
 // Note that using processor with transducers that have side effects is a
 // particularly bad idea. It's also a bad idea to use it if your transduction
 // stack has a high degree of fanout, as the queue can become quite large.
+//
+// If the collection is a Releaser, it is released as soon as the source is
+// exhausted or the pipeline terminates.
 func Eduction(coll interface{}, tlist ...Transducer) ValueStream {
+	vs, _ := EductionContext(context.Background(), coll, tlist...)
+	return vs
+}
+
+// EductionContext is Eduction, but stops feeding the pipeline as soon as the
+// context is cancelled or its deadline passes.
+//
+// Once cancelled, the pipeline is completed just as if the source had been
+// exhausted; any values already queued, or flushed out by Complete, are still
+// returned. After the stream reports being exhausted, the returned func
+// reports ctx.Err() if it was cut short by the context, or nil if it wasn't.
+func EductionContext(ctx context.Context, coll interface{}, tlist ...Transducer) (ValueStream, func() error) {
+	src, release, err := contextStream(ctx, coll)
+	vs, _ := eduction(src, release, tlist...)
+	return vs, err
+}
+
+// Does the work for the Eduction processors. The returned func abandons the
+// eduction: if the pipeline hasn't already been completed, the source is
+// released and the pipeline is completed, discarding anything it flushes.
+func eduction(src ValueStream, release func(), tlist ...Transducer) (ValueStream, func()) {
 	var bottom ReduceStep = func(accum interface{}, value interface{}) (interface{}, bool) {
 		return append(accum.([]interface{}), value), false
 	}
 
-	src := ToStream(coll)
 	pipe := CreatePipeline(bottom, tlist...)
 	var queue []interface{}
 	var input interface{}
 	var exhausted, terminate bool
 
+	stop := func() {
+		if !exhausted && !terminate {
+			terminate = true
+			release()
+			pipe.Complete(queue)
+		}
+		queue = nil
+	}
+
 	return func() (value interface{}, done bool) {
 		// first, check the queue to see if it needs draining
 		if len(queue) > 0 {
@@ -74,6 +216,7 @@ func Eduction(coll interface{}, tlist ...Transducer) ValueStream {
 			input, exhausted = src()
 			if exhausted || terminate {
 				// src is exhausted, send Complete signal
+				release()
 				queue = pipe.Complete(queue).([]interface{})
 				// Complete may have flushed some stuff into the accum/queue
 				if len(queue) > 0 {
@@ -89,6 +232,7 @@ func Eduction(coll interface{}, tlist ...Transducer) ValueStream {
 			queue = value.([]interface{})
 			if terminate {
 				// this is here because it's less horrifying than the alternative
+				release()
 				queue = pipe.Complete(queue).([]interface{})
 			}
 
@@ -96,8 +240,13 @@ func Eduction(coll interface{}, tlist ...Transducer) ValueStream {
 				value, queue = queue[0], queue[1:]
 				return value, false
 			}
+
+			if terminate {
+				// don't go back to the src, it's already been released
+				return nil, true
+			}
 		}
-	}
+	}, stop
 }
 
 // Given a channel, apply the transducer stack to values it produces,
@@ -109,36 +258,98 @@ func Eduction(coll interface{}, tlist ...Transducer) ValueStream {
 // the input channel and consuming on the resultant channel from separate
 // goroutines.
 //
+// Though a channel is the typical input, any collection that the other
+// processors accept will do. In particular, a releasable stream (e.g. from
+// ChanStream) is released when the goroutine stops pulling from it, which a
+// bare channel's producer has no way of finding out.
+//
 // The second parameter determines the buffering of the returned channel (it is
 // passed directly to the make() call).
-func Go(c <-chan interface{}, retcap int, tlist ...Transducer) <-chan interface{} {
+func Go(coll interface{}, retcap int, tlist ...Transducer) <-chan interface{} {
+	out, _ := GoContext(context.Background(), coll, retcap, tlist...)
+	return out
+}
+
+// GoContext is Go, but the goroutine stops as soon as the context is
+// cancelled or its deadline passes - even if it's blocked waiting to receive
+// from an input channel, or waiting to send on the output channel.
+//
+// Complete is always called, and the output channel is always closed, so no
+// goroutine is left behind. Values flushed out by Complete after cancellation
+// are only sent if a receiver is ready for them; otherwise they are dropped.
+//
+// Once the output channel is closed, the error channel receives a single value:
+// ctx.Err() if the transduction was cut short by the context, or nil if it
+// wasn't. It is buffered, so there's no need to receive from it at all.
+func GoContext(ctx context.Context, coll interface{}, retcap int, tlist ...Transducer) (<-chan interface{}, <-chan error) {
 	out := make(chan interface{}, retcap)
-	pipe := CreatePipeline(chanReducer{c: out}, tlist...)
+	errc := make(chan error, 1)
+	pipe := CreatePipeline(chanReducer{c: out, done: ctx.Done()}, tlist...)
+	// the source can also be stopped from within the pipeline
+	srcCtx, cancel := context.WithCancel(ctx)
+	src, release, err := contextStream(srcCtx, coll)
 
-	var accum struct{} // accum is unused in this mode
+	accum := detached{stop: func() {
+		cancel()
+		if rs, ok := coll.(*ReleasableStream); ok {
+			rs.Release()
+		}
+	}}
 	var terminate bool
 
 	go func() {
-		for v := range c {
+		for v, done := src(); !done; v, done = src() {
 			_, terminate = pipe.Step(accum, v)
 			if terminate {
 				break
 			}
 		}
 
+		release()
 		pipe.Complete(accum)
+		cancel()
+
+		e := err()
+		if ctx.Err() == nil {
+			// stopped from within, not by the caller
+			e = nil
+		}
+		if e == nil && terminate {
+			// the chanReducer terminates if the context is cancelled mid-send,
+			// which looks just the same as the stack terminating
+			e = ctx.Err()
+		}
+		errc <- e
+		close(errc)
 	}()
 
-	return out
+	return out, errc
 }
 
+// The accumulator used by the Go processors. Nothing reads it, so transducers
+// that see it know it's safe to step the rest of the pipeline from another
+// goroutine (as long as they don't do it concurrently with their own Step).
+//
+// If a step from another goroutine terminates the pipeline, stop has to be
+// called, as the processor may be blocked waiting on the source.
+type detached struct {
+	stop func()
+}
+
+// Bottom reducer for the Go processors; sends each value into a channel. If
+// done is closed before the send can go through, it terminates instead.
 type chanReducer struct {
-	c chan<- interface{}
+	c    chan<- interface{}
+	done <-chan struct{}
 }
 
 func (c chanReducer) Step(accum interface{}, value interface{}) (interface{}, bool) {
-	c.c <- value
-	return accum, false
+	select {
+	case c.c <- value:
+		return accum, false
+	case <-c.done:
+		return accum, true
+	}
 }
 
 func (c chanReducer) Complete(accum interface{}) interface{} {
@@ -149,3 +360,70 @@ func (c chanReducer) Complete(accum interface{}) interface{} {
 func (c chanReducer) Init() interface{} {
 	return nil
 }
+
+// contextStream converts a processor's input collection to a stream that
+// reports being exhausted once the context is done. Channels (of any element
+// type) are received from in a select, so cancellation isn't held up by a
+// blocked receive; any other kind of source is checked between values.
+//
+// Along with the release func from streamOf, it returns a func reporting
+// ctx.Err() if the stream was cut short by the context, or nil if it wasn't.
+func contextStream(ctx context.Context, coll interface{}) (ValueStream, func(), func() error) {
+	src, release := streamOf(coll)
+
+	var c <-chan interface{}
+	var rc reflect.Value // typed channels have to be selected on via reflection
+	switch ch := coll.(type) {
+	case chan interface{}:
+		c = ch
+	case <-chan interface{}:
+		c = ch
+	case *ReleasableStream:
+		c = ch.c
+	default:
+		if v := reflect.ValueOf(coll); v.Kind() == reflect.Chan && v.Type().ChanDir()&reflect.RecvDir != 0 {
+			rc = v
+		}
+	}
+
+	var err error
+	vs := func() (interface{}, bool) {
+		if err != nil {
+			return nil, true
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, true
+		}
+
+		if ctx.Done() == nil || (c == nil && !rc.IsValid()) {
+			return src()
+		}
+
+		if rc.IsValid() {
+			chosen, v, ok := reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: rc},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			})
+			if chosen == 1 {
+				err = ctx.Err()
+				return nil, true
+			}
+			if !ok {
+				return nil, true
+			}
+			return v.Interface(), false
+		}
+
+		select {
+		case v, ok := <-c:
+			return v, !ok
+		case <-ctx.Done():
+			err = ctx.Err()
+			return nil, true
+		}
+	}
+
+	return vs, release, func() error {
+		return err
+	}
+}
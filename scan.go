@@ -0,0 +1,68 @@
+package transducers
+
+type scan struct {
+	reducerBase
+	acc       interface{}
+	step      func(accum interface{}, value interface{}) (interface{}, bool)
+	complete  func(accum interface{}) interface{}
+	terminate bool
+}
+
+func (r *scan) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	if r.terminate {
+		return accum, true
+	}
+
+	var term bool
+	r.acc, term = r.step(r.acc, value)
+
+	acc := r.acc
+	if vs, ok := acc.(ValueStream); ok {
+		// it'll be read downstream, but we still need it
+		r.acc, acc = vs.Split()
+	}
+
+	accum, r.terminate = r.next.Step(accum, r.complete(acc))
+	r.terminate = r.terminate || term
+	return accum, r.terminate
+}
+
+// Scan reduces the values coming through with the given step func, starting
+// from init, and passes along each intermediate result - so with a step func
+// that adds, it produces a running total. It's Clojure's reductions, except that
+// init itself isn't passed along.
+//
+// If the step func signals termination, its result is still passed along
+// before the pipeline terminates.
+//
+// Each result is passed along as-is, so if the accumulator is a slice or map
+// that the step func modifies, later steps will modify what was passed along
+// earlier, too. ValueStreams are fine: they get split.
+func Scan(init interface{}, step ReduceStep) Transducer {
+	return func(r Reducer) Reducer {
+		return &scan{
+			reducerBase: reducerBase{r},
+			acc:         init,
+			step:        step,
+			complete:    identity,
+		}
+	}
+}
+
+// Reductions is Scan, but with a whole Reducer: the accumulator comes from
+// its Init, and each intermediate result passed along is what its Complete
+// makes of the accumulator at that point (say, a mean from a running sum and
+// count).
+//
+// That means Complete is called after every step, so it must not have side
+// effects, or modify the accumulator.
+func Reductions(r Reducer) Transducer {
+	return func(next Reducer) Reducer {
+		return &scan{
+			reducerBase: reducerBase{next},
+			acc:         r.Init(),
+			step:        r.Step,
+			complete:    r.Complete,
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package transducers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func add(accum interface{}, value interface{}) (interface{}, bool) {
+	return accum.(int) + value.(int), false
+}
+
+func TestScan(t *testing.T) {
+	result := Transduce(Range(5), tb(), Scan(0, add)).([]int)
+	intSliceEquals([]int{0, 1, 3, 6, 10}, result, t)
+
+	// running max
+	max := func(accum interface{}, value interface{}) (interface{}, bool) {
+		if value.(int) > accum.(int) {
+			return value, false
+		}
+		return accum, false
+	}
+	result = Transduce([]int{3, 1, 4, 1, 5, 9, 2, 6}, tb(), Scan(0, max)).([]int)
+	intSliceEquals([]int{3, 3, 4, 4, 5, 9, 9, 9}, result, t)
+}
+
+func TestScanChunks(t *testing.T) {
+	sums := func(accum interface{}, value interface{}) (interface{}, bool) {
+		return accum.(int) + sum(value.(ValueStream)), false
+	}
+	result := Transduce(Range(7), tb(), Chunk(3), Scan(0, sums)).([]int)
+	intSliceEquals([]int{3, 15, 21}, result, t)
+
+	result = Transduce(Range(6), tb(), ChunkBy(func(v interface{}) interface{} {
+		return v.(int) < 4
+	}), Scan(0, sums)).([]int)
+	intSliceEquals([]int{6, 15}, result, t)
+}
+
+func TestScanStreamAccumulator(t *testing.T) {
+	// the chunk stream itself is the accumulator, and gets passed along
+	last := func(accum interface{}, value interface{}) (interface{}, bool) {
+		return value, false
+	}
+	result := ToSlice(Eduction(Range(4), Chunk(2), Scan(nil, last)))
+	if fmt.Sprint(result) != "[[0 1] [2 3]]" {
+		t.Error("Unexpected result", result)
+	}
+}
+
+func TestScanTerminates(t *testing.T) {
+	upTo10 := func(accum interface{}, value interface{}) (interface{}, bool) {
+		accum = accum.(int) + value.(int)
+		return accum, accum.(int) >= 10
+	}
+
+	result := Transduce(naturals(), tb(), Scan(0, upTo10)).([]int)
+	intSliceEquals([]int{0, 1, 3, 6, 10}, result, t)
+	streamEquals(toi(0, 1, 3, 6, 10), Eduction(naturals(), Scan(0, upTo10)), t)
+}
+
+func TestReductions(t *testing.T) {
+	// running mean, from a reducer that keeps a sum and count
+	mean := CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		a := accum.([2]int)
+		return [2]int{a[0] + value.(int), a[1] + 1}, false
+	})
+	mean.I = func() interface{} {
+		return [2]int{}
+	}
+	mean.C = func(accum interface{}) interface{} {
+		a := accum.([2]int)
+		return a[0] / a[1]
+	}
+
+	xf := Reductions(mean)
+	result := Transduce([]int{2, 4, 6, 8}, tb(), xf).([]int)
+	intSliceEquals([]int{2, 3, 4, 5}, result, t)
+
+	// a fresh accumulator for each pipeline
+	result = Transduce([]int{2, 4, 6, 8}, tb(), xf).([]int)
+	intSliceEquals([]int{2, 3, 4, 5}, result, t)
+}
@@ -0,0 +1,139 @@
+package transducers
+
+import (
+	"iter"
+	"reflect"
+)
+
+// A Pair holds a key and a value as a single value. Streams made from
+// two-value iterators (iter.Seq2) produce these.
+type Pair struct {
+	Key, Value interface{}
+}
+
+// FromSeq creates a stream that pulls values from a standard library iterator.
+//
+// The iterator is stopped once it has been run to the end, or when the stream
+// is released; processors will take care of the latter for you. If you use the
+// stream yourself, release it if you don't read it to the end, or the
+// iterator will never finish.
+func FromSeq[T any](seq iter.Seq[T]) *ReleasableStream {
+	next, stop := iter.Pull(seq)
+	return Releasable(func() (interface{}, bool) {
+		v, ok := next()
+		if !ok {
+			stop()
+			return nil, true
+		}
+		return v, false
+	}, stop)
+}
+
+// FromSeq2 is FromSeq for two-value iterators. Each key and value are put
+// together into a Pair.
+func FromSeq2[K, V any](seq iter.Seq2[K, V]) *ReleasableStream {
+	next, stop := iter.Pull2(seq)
+	return Releasable(func() (interface{}, bool) {
+		k, v, ok := next()
+		if !ok {
+			stop()
+			return nil, true
+		}
+		return Pair{k, v}, false
+	}, stop)
+}
+
+// seqStream makes a stream from a collection, if it's an iterator.
+//
+// Iterators over interface{} are handled directly; iterators over any other
+// type have to go through reflection, since there's no way to write a type
+// switch case that matches an iter.Seq[T] for every T.
+func seqStream(coll interface{}) (*ReleasableStream, bool) {
+	switch c := coll.(type) {
+	case iter.Seq[interface{}]:
+		return FromSeq(c), true
+	case func(func(interface{}) bool):
+		return FromSeq(c), true
+	case iter.Seq2[interface{}, interface{}]:
+		return FromSeq2(c), true
+	case func(func(interface{}, interface{}) bool):
+		return FromSeq2(c), true
+	}
+
+	rv := reflect.ValueOf(coll)
+	if !rv.IsValid() || rv.Kind() != reflect.Func || rv.IsNil() || !isSeq(rv.Type()) {
+		return nil, false
+	}
+
+	if rv.Type().In(0).NumIn() == 1 {
+		return FromSeq(func(yield func(interface{}) bool) {
+			for v := range rv.Seq() {
+				if !yield(v.Interface()) {
+					return
+				}
+			}
+		}), true
+	}
+
+	return FromSeq2(func(yield func(interface{}, interface{}) bool) {
+		for k, v := range rv.Seq2() {
+			if !yield(k.Interface(), v.Interface()) {
+				return
+			}
+		}
+	}), true
+}
+
+// Whether a func type has the shape of an iter.Seq or iter.Seq2.
+func isSeq(t reflect.Type) bool {
+	if t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+
+	yield := t.In(0)
+	return yield.Kind() == reflect.Func &&
+		(yield.NumIn() == 1 || yield.NumIn() == 2) &&
+		yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+}
+
+// Seq adapts the stream to a standard library iterator, so it can be used
+// directly in a range loop.
+func (vs ValueStream) Seq() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for v, done := vs(); !done; v, done = vs() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Seq adapts the stream to a standard library iterator. The stream is
+// released when the loop finishes - including if it's broken out of early.
+func (s *ReleasableStream) Seq() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		defer s.Release()
+		s.ValueStream.Seq()(yield)
+	}
+}
+
+// EductionSeq is Eduction, but returns a standard library iterator instead of
+// a ValueStream, so it can be used directly in a range loop:
+//
+//	for v := range EductionSeq(coll, Map(Inc), Filter(Even)) {
+//		...
+//	}
+//
+// The pipeline is created when the loop starts. If the loop is broken out of
+// early, the collection is released (if it's a Releaser) and the pipeline is
+// completed, just as if the pipeline had terminated; anything Complete flushes
+// out is discarded.
+func EductionSeq(coll interface{}, tlist ...Transducer) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		src, release := streamOf(coll)
+		vs, stop := eduction(src, release, tlist...)
+		defer stop()
+
+		vs.Seq()(yield)
+	}
+}
@@ -0,0 +1,126 @@
+package transducers
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// An iter.Seq over [0, n) that records whether it has finished running.
+func countingSeq(n int, finished *bool) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		defer func() {
+			*finished = true
+		}()
+
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestTransduceSeq(t *testing.T) {
+	var finished bool
+	result := Transduce(countingSeq(100, &finished), tb(), Map(Inc), Take(3)).([]int)
+	intSliceEquals([]int{1, 2, 3}, result, t)
+	if !finished {
+		t.Error("Iterator was not stopped when Take terminated")
+	}
+
+	finished = false
+	streamEquals(toi(0, 2, 4), Eduction(countingSeq(5, &finished), Filter(Even)), t)
+	if !finished {
+		t.Error("Iterator was not run to the end")
+	}
+
+	finished = false
+	chanEquals(toi(0, 1), Go(countingSeq(100, &finished), 0, Take(2)), t)
+	if !finished {
+		t.Error("Iterator was not stopped when Take terminated")
+	}
+
+	// an iterator over interface{} takes the direct path
+	var seq iter.Seq[interface{}] = func(yield func(interface{}) bool) {
+		yield(1)
+	}
+	intSliceEquals([]int{2}, Transduce(seq, tb(), Map(Inc)).([]int), t)
+}
+
+func TestTransduceSeq2(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	keys := Transduce(maps.All(m), CreateStep(func(accum interface{}, value interface{}) (interface{}, bool) {
+		p := value.(Pair)
+		return append(accum.([]interface{}), fmt.Sprint(p.Key, p.Value)), false
+	}))
+
+	var got []string
+	for _, k := range keys.([]interface{}) {
+		got = append(got, k.(string))
+	}
+	sort.Strings(got)
+	if fmt.Sprint(got) != "[a1 b2 c3]" {
+		t.Error("Unexpected pairs", got)
+	}
+}
+
+func TestValueStreamSeq(t *testing.T) {
+	var result []int
+	for v := range Range(5).Seq() {
+		if v == 3 {
+			break
+		}
+		result = append(result, v.(int))
+	}
+	intSliceEquals([]int{0, 1, 2}, result, t)
+
+	src, released := releasableRange(5)
+	for v := range src.Seq() {
+		if v == 1 {
+			break
+		}
+	}
+	if *released != 1 {
+		t.Error("Breaking out of the loop did not release the stream")
+	}
+
+	// round trip
+	streamEquals(toi(4, 5), FromSeq(slices.Values([]int{4, 5})).ValueStream, t)
+	var back []interface{}
+	for v := range FromSeq(slices.Values([]int{4, 5})).Seq() {
+		back = append(back, v)
+	}
+	streamEquals(back, ToStream([]int{4, 5}), t)
+}
+
+func TestEductionSeq(t *testing.T) {
+	var result []int
+	for v := range EductionSeq(Range(10), Map(Inc), Filter(Even)) {
+		result = append(result, v.(int))
+	}
+	intSliceEquals([]int{2, 4, 6, 8, 10}, result, t)
+
+	// break out early: the source is released, and Complete is called, which
+	// here means Escape closes its channel
+	src, released := releasableRange(10)
+	escaped := make(chan interface{}, 10)
+	result = nil
+	for v := range EductionSeq(src, Escape(Even, escaped, true), Chunk(2), Mapcat(Flatten)) {
+		result = append(result, v.(int))
+		if len(result) == 2 {
+			break
+		}
+	}
+
+	intSliceEquals([]int{1, 3}, result, t)
+	if *released != 1 {
+		t.Error("Breaking out of the loop did not release the source")
+	}
+	for range escaped {
+		// would block forever if Complete hadn't closed it
+	}
+}
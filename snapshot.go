@@ -0,0 +1,329 @@
+package transducers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Snapshotter is implemented by stateful reducers whose state can be saved,
+// and later restored into a fresh reducer made by the same transducer, so that
+// a long-running process can pick up where it left off.
+//
+// State is encoded with encoding/gob. Any values a reducer holds on to (e.g.
+// a partial chunk) are encoded as interface{}, so types other than the basic
+// ones have to be registered with gob.Register.
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore([]byte) error
+}
+
+func init() {
+	// Dedupe and Distinct may hold these
+	gob.Register(printedKey(""))
+}
+
+func encodeState(state interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(state)
+	return buf.Bytes(), err
+}
+
+func decodeState(data []byte, state interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(state)
+}
+
+func (p *pipeline) snapshot() ([][]byte, error) {
+	stages := make([][]byte, len(p.stages))
+	for k, r := range p.stages {
+		switch s := r.(type) {
+		case Snapshotter:
+			state, err := s.Snapshot()
+			if err != nil {
+				return nil, fmt.Errorf("cannot snapshot %T: %w", r, err)
+			}
+			stages[k] = state
+		case Stateless:
+			if !s.Stateless() {
+				return nil, fmt.Errorf("cannot snapshot %T: %w", r, ErrNotSupported)
+			}
+		default:
+			return nil, fmt.Errorf("cannot snapshot %T: %w", r, ErrNotSupported)
+		}
+	}
+
+	return stages, nil
+}
+
+func (p *pipeline) restore(stages [][]byte) error {
+	if len(stages) != len(p.stages) {
+		return fmt.Errorf("pipeline has %v stages, but %v were snapshotted", len(p.stages), len(stages))
+	}
+
+	for k, r := range p.stages {
+		if s, ok := r.(Snapshotter); ok {
+			if err := s.Restore(stages[k]); err != nil {
+				return fmt.Errorf("cannot restore %T: %w", r, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Pipelines nested inside others (as with Comp) are saved as one stage.
+
+func (p *pipeline) Snapshot() ([]byte, error) {
+	stages, err := p.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return encodeState(stages)
+}
+
+func (p *pipeline) Restore(data []byte) error {
+	var stages [][]byte
+	if err := decodeState(data, &stages); err != nil {
+		return err
+	}
+	return p.restore(stages)
+}
+
+func (r comp) Snapshot() ([]byte, error) {
+	return r.Reducer.(Snapshotter).Snapshot()
+}
+
+func (r comp) Restore(data []byte) error {
+	return r.Reducer.(Snapshotter).Restore(data)
+}
+
+// SnapshotPipeline saves the state of a pipeline created by CreatePipeline,
+// one stage for each of the transducers it was created from. Stateless stages
+// have nil state; stateful ones have to be Snapshotters, or it returns an error
+// wrapping ErrNotSupported. The bottom reducer's state is all in the
+// accumulator, so it isn't saved.
+func SnapshotPipeline(r Reducer) ([][]byte, error) {
+	p, ok := r.(*pipeline)
+	if !ok {
+		return nil, fmt.Errorf("cannot snapshot %T, it's not from CreatePipeline: %w", r, ErrNotSupported)
+	}
+	return p.snapshot()
+}
+
+// RestorePipeline restores state saved by SnapshotPipeline into a new
+// pipeline, created from the same stack of transducers as the old one.
+func RestorePipeline(r Reducer, stages [][]byte) error {
+	p, ok := r.(*pipeline)
+	if !ok {
+		return fmt.Errorf("cannot restore %T, it's not from CreatePipeline: %w", r, ErrNotSupported)
+	}
+	return p.restore(stages)
+}
+
+// A Checkpoint is everything needed to resume a transduction process: how
+// many values had been taken from the source, the accumulator, and the state
+// of each stage of the pipeline. It can be encoded with encoding/gob, as long
+// as the accumulator's type is registered.
+type Checkpoint struct {
+	Offset int
+	Accum  interface{}
+	Stages [][]byte
+}
+
+// TransduceResumable is Transduce, but every n values taken from the source,
+// the process is checkpointed, and the Checkpoint handed to save. It has to
+// be done with the Checkpoint (e.g. have written it out) by the time save
+// returns, as the accumulator will carry on being used.
+//
+// If from is non-nil, the process resumes from that checkpoint: the pipeline
+// is restored, and from.Offset values are skipped at the start of the source -
+// so the collection must produce the same values, in the same order, as the
+// one the checkpoint was taken from.
+//
+// If save returns an error, the process stops, and the error is returned,
+// along with the accumulator. Complete is not called, as the process is
+// presumably going to be resumed from the last checkpoint.
+func TransduceResumable(coll interface{}, bottom Reducer, from *Checkpoint, n int, save func(Checkpoint) error, tlist ...Transducer) (interface{}, error) {
+	if n < 1 {
+		panic("must checkpoint at least every value")
+	}
+
+	t := CreatePipeline(bottom, tlist...)
+	vs, release := streamOf(coll)
+
+	var ret interface{}
+	var offset int
+	if from != nil {
+		if err := RestorePipeline(t, from.Stages); err != nil {
+			release()
+			return nil, err
+		}
+		for offset < from.Offset {
+			if _, done := vs(); done {
+				break
+			}
+			offset++
+		}
+		ret = from.Accum
+	} else {
+		ret = t.Init()
+	}
+
+	var terminate bool
+	for v, done := vs(); !done; v, done = vs() {
+		ret, terminate = t.Step(ret, v)
+		offset++
+		if terminate {
+			break
+		}
+
+		if offset%n == 0 {
+			stages, err := SnapshotPipeline(t)
+			if err == nil {
+				err = save(Checkpoint{offset, ret, stages})
+			}
+			if err != nil {
+				release()
+				return ret, err
+			}
+		}
+	}
+
+	release()
+	return t.Complete(ret), nil
+}
+
+/* Snapshotter implementations */
+
+type chunkState struct {
+	Coll      []interface{}
+	Terminate bool
+}
+
+func (t *chunk) Snapshot() ([]byte, error) {
+	return encodeState(chunkState{[]interface{}(t.coll[:t.count]), t.terminate})
+}
+
+func (t *chunk) Restore(data []byte) error {
+	var s chunkState
+	if err := decodeState(data, &s); err != nil {
+		return err
+	}
+	if len(s.Coll) > t.length {
+		return fmt.Errorf("chunk of %v values doesn't fit in length %v", len(s.Coll), t.length)
+	}
+
+	t.count = copy(t.coll, s.Coll)
+	t.terminate = s.Terminate
+	return nil
+}
+
+type chunkByState struct {
+	First     bool
+	Last      interface{}
+	Coll      []interface{}
+	Terminate bool
+}
+
+func (t *chunkBy) Snapshot() ([]byte, error) {
+	return encodeState(chunkByState{t.first, t.last, []interface{}(t.coll), t.terminate})
+}
+
+func (t *chunkBy) Restore(data []byte) error {
+	var s chunkByState
+	if err := decodeState(data, &s); err != nil {
+		return err
+	}
+
+	t.first, t.last, t.coll, t.terminate = s.First, s.Last, valueSlice(s.Coll), s.Terminate
+	return nil
+}
+
+type dedupeState struct {
+	Last interface{}
+	Any  bool
+}
+
+func (r *dedupe) Snapshot() ([]byte, error) {
+	return encodeState(dedupeState{r.last, r.any})
+}
+
+func (r *dedupe) Restore(data []byte) error {
+	var s dedupeState
+	if err := decodeState(data, &s); err != nil {
+		return err
+	}
+
+	r.last, r.any = s.Last, s.Any
+	return nil
+}
+
+func (r *distinct) Snapshot() ([]byte, error) {
+	seen := make([]interface{}, 0, len(r.seen))
+	for k := range r.seen {
+		seen = append(seen, k)
+	}
+	return encodeState(seen)
+}
+
+func (r *distinct) Restore(data []byte) error {
+	var seen []interface{}
+	if err := decodeState(data, &seen); err != nil {
+		return err
+	}
+
+	r.seen = make(map[interface{}]struct{}, len(seen))
+	for _, k := range seen {
+		r.seen[k] = struct{}{}
+	}
+	return nil
+}
+
+func (r *take) Snapshot() ([]byte, error) {
+	return encodeState(r.count)
+}
+
+func (r *take) Restore(data []byte) error {
+	return decodeState(data, &r.count)
+}
+
+func (r *drop) Snapshot() ([]byte, error) {
+	return encodeState(r.count)
+}
+
+func (r *drop) Restore(data []byte) error {
+	return decodeState(data, &r.count)
+}
+
+func (r *dropWhile) Snapshot() ([]byte, error) {
+	return encodeState(r.accepted)
+}
+
+func (r *dropWhile) Restore(data []byte) error {
+	return decodeState(data, &r.accepted)
+}
+
+func (r *keepIndexed) Snapshot() ([]byte, error) {
+	return encodeState(r.count)
+}
+
+func (r *keepIndexed) Restore(data []byte) error {
+	return decodeState(data, &r.count)
+}
+
+// TakeWhile and Escape have no state, but aren't Stateless.
+
+func (r takeWhile) Snapshot() ([]byte, error) {
+	return nil, nil
+}
+
+func (r takeWhile) Restore([]byte) error {
+	return nil
+}
+
+func (r escape) Snapshot() ([]byte, error) {
+	return nil, nil
+}
+
+func (r escape) Restore([]byte) error {
+	return nil
+}
@@ -0,0 +1,190 @@
+package transducers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+)
+
+var errCrash = errors.New("crash")
+
+func resumableStack() []Transducer {
+	sum := func(v interface{}) interface{} {
+		var total int
+		v.(ValueStream).Each(func(v interface{}) {
+			total += v.(int)
+		})
+		return total
+	}
+
+	return []Transducer{
+		Drop(3),
+		// goes around twice, so Distinct has repeats to drop after a restore
+		Map(func(v interface{}) interface{} { return v.(int) % 40 / 2 }),
+		Dedupe(),
+		Distinct(),
+		DropWhile(func(v interface{}) bool { return v.(int) < 4 }),
+		KeepIndexed(func(i int, v interface{}) interface{} {
+			if i%3 == 2 {
+				return nil
+			}
+			return v
+		}),
+		Chunk(3),
+		Map(sum),
+		Take(6),
+	}
+}
+
+func TestTransduceResumable(t *testing.T) {
+	src := t_range(60)
+	expected := Transduce(src, Append(), resumableStack()...).([]int)
+
+	// checkpoint every 7 values, crashing after the fourth, and pass the last
+	// one through gob on the way, as it would be if it had been saved
+	var saved []byte
+	var saves int
+	save := func(c Checkpoint) error {
+		saves++
+		if saves > 4 {
+			return errCrash
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+			t.Fatal(err)
+		}
+		saved = buf.Bytes()
+		return nil
+	}
+
+	_, err := TransduceResumable(src, Append(), nil, 7, save, resumableStack()...)
+	if err != errCrash {
+		t.Fatal("Expected the save error to be returned, got", err)
+	}
+
+	var from Checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(saved)).Decode(&from); err != nil {
+		t.Fatal(err)
+	}
+	if from.Offset != 28 {
+		t.Error("Expected the last checkpoint to be at offset 28, got", from.Offset)
+	}
+
+	result, err := TransduceResumable(src, Append(), &from, 7, func(Checkpoint) error { return nil }, resumableStack()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intSliceEquals(expected, result.([]int), t)
+}
+
+func TestRestorePipeline(t *testing.T) {
+	p := CreatePipeline(Append(), Comp(Drop(1), Chunk(2)), Dedupe())
+	acc := p.Init()
+	for _, v := range []int{9, 1, 1, 2} {
+		acc, _ = p.Step(acc, v)
+	}
+
+	stages, err := SnapshotPipeline(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stages) != 2 {
+		t.Fatal("Expected state for 2 stages, got", len(stages))
+	}
+
+	// picks up with 2 waiting in the chunk
+	q := CreatePipeline(Append(), Comp(Drop(1), Chunk(2)), Dedupe())
+	if err := RestorePipeline(q, stages); err != nil {
+		t.Fatal(err)
+	}
+	acc, _ = q.Step(acc, 3)
+	acc, _ = q.Step(acc, 3)
+	intSliceEquals([]int{1, 1, 2, 3, 3}, q.Complete(acc).([]int), t)
+
+	if err := RestorePipeline(CreatePipeline(Append(), Drop(1)), stages); err == nil {
+		t.Error("Expected an error restoring into a pipeline with fewer stages")
+	}
+}
+
+func TestSnapshotUnsupported(t *testing.T) {
+	p := CreatePipeline(Append(), Map(Inc), Partition(2, 1))
+	if _, err := SnapshotPipeline(p); !errors.Is(err, ErrNotSupported) {
+		t.Error("Expected Partition not to be snapshottable, got", err)
+	}
+}
+
+// Passes along every other value; a custom stateful stage.
+type everyOther struct {
+	next Reducer
+	odd  bool
+}
+
+func (r *everyOther) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	r.odd = !r.odd
+	if !r.odd {
+		return accum, false
+	}
+	return r.next.Step(accum, value)
+}
+
+func (r *everyOther) Complete(accum interface{}) interface{} {
+	return r.next.Complete(accum)
+}
+
+func (r *everyOther) Init() interface{} {
+	return r.next.Init()
+}
+
+type snapshottableEveryOther struct {
+	everyOther
+}
+
+func (r *snapshottableEveryOther) Snapshot() ([]byte, error) {
+	return encodeState(r.odd)
+}
+
+func (r *snapshottableEveryOther) Restore(data []byte) error {
+	return decodeState(data, &r.odd)
+}
+
+func TestSnapshotCustomStage(t *testing.T) {
+	stack := func() []Transducer {
+		return []Transducer{Map(Inc), func(r Reducer) Reducer {
+			return &snapshottableEveryOther{everyOther{next: r}}
+		}, Take(5)}
+	}
+
+	p := CreatePipeline(Append(), stack()...)
+	acc := p.Init()
+	for _, v := range []int{0, 1, 2} {
+		acc, _ = p.Step(acc, v)
+	}
+
+	stages, err := SnapshotPipeline(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the custom stage and Take below it both pick up where they left off
+	q := CreatePipeline(Append(), stack()...)
+	if err := RestorePipeline(q, stages); err != nil {
+		t.Fatal(err)
+	}
+	for v := 3; v < 20; v++ {
+		var terminate bool
+		if acc, terminate = q.Step(acc, v); terminate {
+			break
+		}
+	}
+	intSliceEquals([]int{1, 3, 5, 7, 9}, q.Complete(acc).([]int), t)
+
+	// one that can't be snapshotted is an error, not skipped
+	p = CreatePipeline(Append(), Map(Inc), func(r Reducer) Reducer {
+		return &everyOther{next: r}
+	}, Take(5))
+	if _, err := SnapshotPipeline(p); !errors.Is(err, ErrNotSupported) {
+		t.Error("Expected a stateful custom stage not to be snapshottable, got", err)
+	}
+}
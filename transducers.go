@@ -1,6 +1,9 @@
 package transducers
 
-import "math/rand"
+import (
+	"fmt"
+	"math/rand"
+)
 
 // The master signature: a reducing step function.
 type ReduceStep func(accum interface{}, value interface{}) (result interface{}, terminate bool)
@@ -10,6 +13,13 @@ type Transducer func(Reducer) Reducer
 
 // This is separated out because, while Transducers must support Init() (in order to pass
 // the call along), not all processors require Init, so they may take a partial step instead.
+//
+// Early termination works like Clojure's reduced, but with a bool in place of
+// the wrapper: once Step returns true for terminate, the process is over, and
+// Step must never be called on that reducer again - not by a processor, and not
+// by a transducer flushing held state from Complete. A transducer that gets
+// terminate back from the next reducer has to pass it along, and stop
+// stepping. Complete is still called, exactly once, either way.
 type Reducer interface {
 	// The primary reducing step function, called during normal operation.
 	Step(accum interface{}, value interface{}) (result interface{}, terminate bool) // Reducer
@@ -32,15 +42,23 @@ type Reducer interface {
 //
 // This function is usually called by processors (Transduce, Eduction, etc.).
 // If you're using one of those, they'll call it when the time is right.
-func CreatePipeline(r Reducer, tds ...Transducer) (rs Reducer) {
-	rs = Reducer(r)
+func CreatePipeline(r Reducer, tds ...Transducer) Reducer {
+	p := &pipeline{Reducer: r, stages: make([]Reducer, len(tds))}
 	// Because a pipeline is a series of wrapped functions, we must walk the list
 	// in reverse order and apply each transducer, starting from the bottom reducer.
 	for i := len(tds) - 1; i >= 0; i-- {
-		rs = tds[i](rs)
+		p.Reducer = tds[i](p.Reducer)
+		p.stages[i] = p.Reducer
 	}
 
-	return
+	return p
+}
+
+// A pipeline hangs on to the reducer made by each of its transducers, top
+// down, so that their state can be saved (see SnapshotPipeline).
+type pipeline struct {
+	Reducer
+	stages []Reducer
 }
 
 func (r ReduceStep) Step(accum interface{}, value interface{}) (result interface{}, terminate bool) {
@@ -110,18 +128,37 @@ func Filter(f Filterer) Transducer {
 type append_bottom struct{}
 
 func (r append_bottom) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	accum, err := appendInts(accum.([]int), value)
+	if err != nil {
+		panic("not supported")
+	}
+	return accum, false
+}
+
+// Does the work for both Append and AppendErr.
+func appendInts(accum []int, value interface{}) ([]int, error) {
 	switch v := value.(type) {
 	case []int:
-		return append(accum.([]int), v...), false
+		return append(accum, v...), nil
 	case int:
-		return append(accum.([]int), v), false
+		return append(accum, v), nil
 	case ValueStream:
+		// all or nothing - if any value in the stream is bad, none are appended
+		var ints []int
+		var err error
 		v.Flatten().Each(func(value interface{}) {
-			accum = append(accum.([]int), value.(int))
+			if i, ok := value.(int); ok && err == nil {
+				ints = append(ints, i)
+			} else if err == nil {
+				err = fmt.Errorf("cannot append %T from stream to []int: %w", value, ErrNotSupported)
+			}
 		})
-		return accum, false
+		if err != nil {
+			return accum, err
+		}
+		return append(accum, ints...), nil
 	default:
-		panic("not supported")
+		return accum, fmt.Errorf("cannot append %T to []int: %w", value, ErrNotSupported)
 	}
 }
 
@@ -173,31 +210,32 @@ func Mapcat(f Exploder) Transducer {
 
 type dedupe struct {
 	reducerBase
-	// TODO Slice is fine for prototype, but should replace with type-appropriate
-	// search tree later
-	seen valueSlice
+	key  Mapper
+	last interface{}
+	any  bool // whether last has been set yet
 }
 
 func (r *dedupe) Step(accum interface{}, value interface{}) (interface{}, bool) {
-	for _, v := range r.seen {
-		if value == v {
-			return accum, false
-		}
+	k, value := keyOf(r.key, value)
+	if r.any && k == r.last {
+		return accum, false
 	}
 
-	r.seen = append(r.seen, value)
+	r.last, r.any = k, true
 	return r.next.Step(accum, value)
 }
 
-// Dedupe keeps track of values that have passed through it during this
-// transduction process and drops any duplicates.
+// Dedupe drops any value that's the same as the one just before it, as
+// Clojure's dedupe does. To drop every repeat, no matter how far apart, use
+// Distinct.
 //
-// Simple equality (==) is used for comparison. That will panic on non-hashable
-// datastructures (maps, slices, channels)!
+// Simple equality (==) is used for comparison, where it can be. Values that
+// can't be compared that way (slices, maps, or structs containing them) are
+// compared by their contents instead - element by element, and as with ==,
+// values of different types are never the same. ValueStreams are compared by
+// their contents, too.
 func Dedupe() Transducer {
-	return func(r Reducer) Reducer {
-		return &dedupe{reducerBase{r}, make([]interface{}, 0)}
-	}
+	return DedupeBy(identity)
 }
 
 // Condense the traversed collection by partitioning it into
@@ -315,15 +353,33 @@ type randomSample struct {
 
 // Passes the received value along to the next transducer, with the
 // given probability.
+//
+// Randomness comes from the math/rand package's global source; use
+// RandomSampleSource to make sampling reproducible.
 func RandomSample(ρ float64) Transducer {
+	return randomSampleFrom(ρ, func() func() float64 {
+		return rand.Float64
+	})
+}
+
+// RandomSampleSource is RandomSample, but each pipeline draws from its own
+// source, created by calling src. Have src return a source seeded the same way
+// every time, and each pipeline will sample the same values.
+func RandomSampleSource(ρ float64, src func() rand.Source) Transducer {
+	return randomSampleFrom(ρ, func() func() float64 {
+		return rand.New(src()).Float64
+	})
+}
+
+func randomSampleFrom(ρ float64, floats func() func() float64) Transducer {
 	if ρ < 0.0 || ρ > 1.0 {
 		panic("ρ must be in the range [0.0,1.0].")
 	}
 
 	return func(r Reducer) Reducer {
+		float := floats()
 		return randomSample{filter{reducerBase{r}, func(_ interface{}) bool {
-			//panic("oh shit")
-			return rand.Float64() < ρ
+			return float() < ρ
 		}}}
 	}
 }
@@ -334,9 +390,8 @@ type takeNth struct {
 
 // TakeNth takes every nth element to pass through it, discarding the remainder.
 func TakeNth(n int) Transducer {
-	var count int
-
 	return func(r Reducer) Reducer {
+		var count int // per pipeline
 		return takeNth{filter{reducerBase{r}, func(_ interface{}) bool {
 			count++ // TODO atomic
 			return count%n == 0
@@ -417,17 +472,20 @@ type take struct {
 }
 
 func (r *take) Step(accum interface{}, value interface{}) (interface{}, bool) {
-	r.count++ // TODO atomic
-	if r.count < r.max {
-		return r.next.Step(accum, value)
+	if r.count >= r.max {
+		// only reachable with Take(0)
+		return accum, true
 	}
-	// should NEVER be called again after this. add a panic branch?
-	accum, _ = r.next.Step(accum, value)
-	return accum, true
+
+	r.count++ // TODO atomic
+	accum, terminate := r.next.Step(accum, value)
+	return accum, terminate || r.count == r.max
 }
 
 // Take specifies a maximum number of values to receive, after which it will
-// terminate the transducing process.
+// terminate the transducing process. It terminates as soon as the last of
+// them has gone through, without waiting for another value to arrive; Take(0)
+// terminates on the first value, without passing it along.
 func Take(max uint) Transducer {
 	return func(r Reducer) Reducer {
 		return &take{reducerBase{r}, max, 0}
@@ -1,8 +1,12 @@
 package transducers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
+	"time"
 )
 
 var ints = []int{1, 2, 3, 4, 5}
@@ -102,13 +106,13 @@ func TestTransduceMapFilterMapcat(t *testing.T) {
 	intSliceEquals([]int{0, 1, 2, 0, 1, 2, 3, 4}, result, t)
 }
 
-func TestTransduceMapFilterMapcatDedupe(t *testing.T) {
-	xform := []Transducer{Filter(Even), Map(Inc), Mapcat(Range), Dedupe()}
+func TestTransduceMapFilterMapcatDistinct(t *testing.T) {
+	xform := []Transducer{Filter(Even), Map(Inc), Mapcat(Range), Distinct()}
 
 	result := Transduce(ToStream(ints), tb(), dt(xform)...).([]int)
 	intSliceEquals([]int{0, 1, 2, 3, 4}, result, t)
 
-	// Dedupe is stateful. Do it twice to demonstrate that's handled
+	// Distinct is stateful. Do it twice to demonstrate that's handled
 	result2 := Transduce(ToStream(ints), tb(), dt(xform)...).([]int)
 	intSliceEquals([]int{0, 1, 2, 3, 4}, result2, t)
 }
@@ -158,10 +162,30 @@ func TestTransduceSample(t *testing.T) {
 	}
 }
 
+func TestRandomSampleSource(t *testing.T) {
+	td := RandomSampleSource(0.5, func() rand.Source {
+		return rand.NewSource(42)
+	})
+
+	result := Transduce(Range(100), tb(), td).([]int)
+	if len(result) == 0 || len(result) == 100 {
+		t.Error("Sampling at 0.5 should drop some values, but not all")
+	}
+
+	// a fresh source for each pipeline, seeded the same way
+	result2 := Transduce(Range(100), tb(), td).([]int)
+	intSliceEquals(result, result2, t)
+}
+
 func TestTakeNth(t *testing.T) {
-	result := Transduce(Range(21), tb(), TakeNth(7)).([]int)
+	td := TakeNth(7)
+	result := Transduce(Range(21), tb(), td).([]int)
 
 	intSliceEquals([]int{6, 13, 20}, result, t)
+
+	// the count belongs to the pipeline, not the transducer
+	result = Transduce(Range(10), tb(), td).([]int)
+	intSliceEquals([]int{6}, result, t)
 }
 
 func TestKeep(t *testing.T) {
@@ -403,3 +427,352 @@ func TestGo(t *testing.T) {
 
 	// feels like there are more permutations to check
 }
+
+// A releasable Range that counts how many times it's been released.
+func releasableRange(i int) (*ReleasableStream, *int) {
+	var released int
+	return Releasable(Range(i), func() {
+		released++
+	}), &released
+}
+
+func TestTransduceChan(t *testing.T) {
+	result := Transduce(rchan(5), tb(), Map(Inc)).([]int)
+	intSliceEquals([]int{1, 2, 3, 4, 5}, result, t)
+}
+
+func TestProcessorsRelease(t *testing.T) {
+	src, released := releasableRange(50)
+	result := Transduce(src, tb(), Take(3)).([]int)
+	intSliceEquals([]int{0, 1, 2}, result, t)
+	if *released != 1 {
+		t.Error("Transduce did not release its source on termination")
+	}
+
+	src, released = releasableRange(50)
+	streamEquals(toi(0, 1, 2), Eduction(src, Take(3)), t)
+	if *released != 1 {
+		t.Error("Eduction did not release its source on termination")
+	}
+
+	src, released = releasableRange(5)
+	streamEquals(toi(1, 2, 3, 4, 5), Eduction(src, Map(Inc)), t)
+	if *released != 1 {
+		t.Error("Eduction did not release its source on exhaustion")
+	}
+
+	src, released = releasableRange(50)
+	chanEquals(toi(0, 1, 2), Go(src, 0, Take(3)), t)
+	if *released != 1 {
+		t.Error("Go did not release its source on termination")
+	}
+}
+
+func TestChanStreamRelease(t *testing.T) {
+	stopped := make(chan struct{})
+	src := Releasable(Range(1000), func() {
+		close(stopped)
+	})
+
+	result := Transduce(ChanStream(src, 0), tb(), Take(3)).([]int)
+	intSliceEquals([]int{0, 1, 2}, result, t)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("ChanStream goroutine was left running after Take terminated")
+	}
+}
+
+func TestReleasableSplit(t *testing.T) {
+	src, released := releasableRange(3)
+	s1, s2 := src.Split()
+
+	streamEquals(toi(0, 1, 2), s1.ValueStream, t)
+	s1.Release()
+	if *released != 0 {
+		t.Error("Source released while one split stream was still live")
+	}
+
+	streamEquals(toi(0, 1, 2), s2.ValueStream, t)
+	s2.Release()
+	s2.Release()
+	if *released != 1 {
+		t.Error("Source should be released exactly once, after both splits are, but was released", *released, "times")
+	}
+}
+
+func TestReleasableFlatten(t *testing.T) {
+	inner, innerReleased := releasableRange(5)
+	outer, outerReleased := releasableRange(2)
+	src := Releasable(valueSlice{outer, inner}.AsStream(), func() {})
+
+	flat := src.Flatten()
+	streamEquals(toi(0, 1, 0, 1), Eduction(flat, Take(4)), t)
+
+	if *outerReleased != 1 {
+		t.Error("Exhausted inner stream was not released")
+	}
+	if *innerReleased != 1 {
+		t.Error("Partially consumed inner stream was not released with the flattener")
+	}
+}
+
+// An infinite stream of ints, [0 1 2 ...
+func naturals() ValueStream {
+	var i int
+	return func() (interface{}, bool) {
+		i++
+		return i - 1, false
+	}
+}
+
+func TestTransduceContext(t *testing.T) {
+	result, err := TransduceContext(context.Background(), Range(5), tb(), Map(Inc))
+	intSliceEquals([]int{1, 2, 3, 4, 5}, result.([]int), t)
+	if err != nil {
+		t.Error("Unexpected error from uncancelled transduction:", err)
+	}
+
+	// cancel partway through an infinite stream; Complete still flushes the chunk
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelAt := func(value interface{}) interface{} {
+		if value.(int) == 4 {
+			cancel()
+		}
+		return value
+	}
+	result, err = TransduceContext(ctx, naturals(), tb(), Map(cancelAt), Chunk(3), Mapcat(Flatten))
+	intSliceEquals([]int{0, 1, 2, 3, 4}, result.([]int), t)
+	if err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestEductionContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	res, err := EductionContext(ctx, naturals(), Map(Inc), Chunk(2), Mapcat(Flatten))
+
+	// 4 was already queued up when the cancel happened, so it still comes out
+	streamEquals(toi(1, 2, 3, 4), func() (interface{}, bool) {
+		v, done := res()
+		if v == 3 {
+			cancel()
+		}
+		return v, done
+	}, t)
+	if err() != context.Canceled {
+		t.Error("Expected context.Canceled, got", err())
+	}
+}
+
+func TestGoContext(t *testing.T) {
+	// the input is never closed, so only cancellation can stop this
+	in := make(chan interface{})
+	go func() {
+		in <- 1
+		in <- 2
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	out, errc := GoContext(ctx, in, 0, Map(Inc))
+	chanEquals(toi(2, 3), out, t)
+	if err := <-errc; err != context.DeadlineExceeded {
+		t.Error("Expected context.DeadlineExceeded, got", err)
+	}
+
+	// cancelled while blocked on sending output that nobody is receiving
+	ctx, cancel = context.WithCancel(context.Background())
+	out, errc = GoContext(ctx, Range(5), 0, Map(Inc))
+	if v := <-out; v != 1 {
+		t.Error("Expected 1, got", v)
+	}
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+
+	out, errc = GoContext(context.Background(), Range(3), 0)
+	chanEquals(toi(0, 1, 2), out, t)
+	if err := <-errc; err != nil {
+		t.Error("Unexpected error from uncancelled transduction:", err)
+	}
+}
+
+func TestTeeConcurrent(t *testing.T) {
+	streams, err := Range(200).Tee(3, 5, LagBlock)
+
+	results := make([][]int, len(streams))
+	done := make(chan struct{})
+	for k, s := range streams {
+		go func(k int, s ValueStream) {
+			s.Each(func(v interface{}) {
+				results[k] = append(results[k], v.(int))
+			})
+			done <- struct{}{}
+		}(k, s.ValueStream)
+	}
+
+	for range streams {
+		<-done
+	}
+
+	for _, result := range results {
+		intSliceEquals(t_range(200), result, t)
+	}
+	if err() != nil {
+		t.Error("Unexpected error:", err())
+	}
+}
+
+func TestTeeLagPolicies(t *testing.T) {
+	streams, err := Range(10).Tee(2, 3, LagError)
+	streamEquals(toi(0, 1, 2), streams[0].ValueStream, t)
+	if err() != ErrLagExceeded {
+		t.Error("Expected ErrLagExceeded, got", err())
+	}
+	// the lagging stream is unaffected
+	streamEquals(toi(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), streams[1].ValueStream, t)
+
+	streams, _ = Range(10).Tee(2, 3, LagDrop)
+	streamEquals(toi(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), streams[0].ValueStream, t)
+	streamEquals(toi(7, 8, 9), streams[1].ValueStream, t)
+
+	// released streams don't count towards the lag
+	streams, err = Range(10).Tee(2, 3, LagError)
+	streams[1].Release()
+	streamEquals(toi(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), streams[0].ValueStream, t)
+	if err() != nil {
+		t.Error("Unexpected error:", err())
+	}
+}
+
+func TestTeeNested(t *testing.T) {
+	src, released := releasableRange(4)
+	chunked := Releasable(Eduction(src, Chunk(2)), src.Release)
+	streams, _ := chunked.Tee(3, 0, LagBlock)
+
+	for _, s := range streams {
+		if fmt.Sprint(ToSlice(s.ValueStream)) != "[[0 1] [2 3]]" {
+			t.Error("Nested streams were not tee'd")
+		}
+	}
+
+	streams[0].Release()
+	streams[1].Release()
+	if *released != 1 {
+		t.Error("Eduction should have released the source once exhausted")
+	}
+	streams[2].Release()
+	if *released != 1 {
+		t.Error("Source should be released exactly once, but was released", *released, "times")
+	}
+}
+
+func TestTeeNestedGone(t *testing.T) {
+	nested := func() ValueStream {
+		return ToStream(toi(Range(10)))
+	}
+	within := func(f func()) {
+		done := make(chan struct{})
+		go func() {
+			f()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Deadlocked waiting on a released stream")
+		}
+	}
+
+	// released before the nested stream turns up
+	streams, _ := nested().Tee(2, 3, LagBlock)
+	streams[1].Release()
+	within(func() {
+		if s := fmt.Sprint(ToSlice(streams[0].ValueStream)); s != "[[0 1 2 3 4 5 6 7 8 9]]" {
+			t.Error("Expected the whole nested stream, got", s)
+		}
+	})
+
+	// released after getting it
+	streams, _ = nested().Tee(2, 3, LagBlock)
+	first, _ := streams[0].ValueStream()
+	streams[1].ValueStream()
+	streams[1].Release()
+	within(func() {
+		streamEquals(toi(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), first.(ValueStream), t)
+	})
+
+	// errors from nested tees are reported
+	streams, err := nested().Tee(2, 3, LagError)
+	first, _ = streams[0].ValueStream()
+	streamEquals(toi(0, 1, 2), first.(ValueStream), t)
+	if err() != ErrLagExceeded {
+		t.Error("Expected ErrLagExceeded from the nested stream, got", err())
+	}
+}
+
+func TestToStreamReflect(t *testing.T) {
+	streamEquals(toi("a", "b"), ToStream([]string{"a", "b"}), t)
+	streamEquals(toi(int8(1), int8(2)), ToStream([]int8{1, 2}), t)
+	streamEquals(toi(1.5, 2.5), ToStream([2]float64{1.5, 2.5}), t)
+	streamEquals(toi('h', 'é', 'y'), ToStream("héy"), t)
+	streamEquals(toi(byte('h'), byte('i')), ToStream([]byte("hi")), t)
+
+	pairs := ToSlice(ToStream(map[string]int{"a": 1}))
+	if len(pairs) != 1 || pairs[0] != (Pair{"a", 1}) {
+		t.Error("Maps should stream key/value pairs, got", pairs)
+	}
+
+	c := make(chan string, 2)
+	c <- "x"
+	c <- "y"
+	close(c)
+	streamEquals(toi("x", "y"), ToStream((<-chan string)(c)), t)
+
+	if _, err := ToStreamErr(make(chan<- int)); !errors.Is(err, ErrNotSupported) {
+		t.Error("Send-only channels should not be streamable")
+	}
+	if _, err := ToStreamErr(42); !errors.Is(err, ErrNotSupported) {
+		t.Error("Ints should not be streamable")
+	}
+}
+
+func TestGoContextTypedChan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out, errc := GoContext(ctx, in, 0, Map(Inc))
+
+	in <- 1
+	if v := <-out; v != 2 {
+		t.Error("Expected 2, got", v)
+	}
+
+	// nothing more is coming on in; cancelling must not wait on it
+	cancel()
+	for range out {
+	}
+	if err := <-errc; err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestTakeTerminatesAtMax(t *testing.T) {
+	var pulled int
+	src := ValueStream(func() (interface{}, bool) {
+		pulled++
+		return pulled, false
+	})
+
+	result := Transduce(src, tb(), Take(3)).([]int)
+	intSliceEquals([]int{1, 2, 3}, result, t)
+	if pulled != 3 {
+		t.Error("Take should terminate on its last value, but", pulled, "were pulled")
+	}
+
+	result = Transduce(Range(5), tb(), Take(0)).([]int)
+	intSliceEquals([]int{}, result, t)
+}
@@ -0,0 +1,259 @@
+// Package transducerstest checks Transducer implementations against the laws
+// every transducer is expected to follow, so that homegrown ones can be mixed
+// with the shipped ones (and each other) without surprises.
+//
+// A transducer passes if, over a run of randomized inputs:
+//
+//   - Init and Complete are each passed along exactly once.
+//   - Once the next reducer signals termination, it's never stepped again -
+//     not even from Complete - and the termination is passed back up.
+//   - Pipelines created from it don't share state: running two at once, or one
+//     after the other, gives the same results as running one alone.
+//   - Transduce, Eduction and Go all produce the same results with it.
+//
+// The last two checks mean it has to be deterministic; one that samples at
+// random won't pass.
+package transducerstest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sdboyer/transducers-go"
+)
+
+// Config tunes the inputs a transducer is checked against.
+type Config struct {
+	// Gen generates an input. If nil, inputs are slices of up to 50 random
+	// ints in [0, 20). Values shouldn't be ValueStreams, as each input is run
+	// through several pipelines.
+	Gen func(r *rand.Rand) []interface{}
+	// Runs is how many inputs each check is run against. Defaults to 100.
+	Runs int
+	// Seed seeds the random inputs. If zero, one is picked from the clock; it's
+	// logged on failure, so a failing run can be reproduced.
+	Seed int64
+}
+
+// Check checks a transducer against all the laws, with the default Config,
+// reporting any violations through t.
+func Check(t testing.TB, td transducers.Transducer) {
+	t.Helper()
+	CheckConfig(t, td, Config{})
+}
+
+// CheckConfig is Check, with a custom Config.
+func CheckConfig(t testing.TB, td transducers.Transducer, c Config) {
+	t.Helper()
+
+	if c.Gen == nil {
+		c.Gen = randomInts
+	}
+	if c.Runs == 0 {
+		c.Runs = 100
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(c.Seed))
+
+	checks := []struct {
+		name  string
+		check func(td transducers.Transducer, input []interface{}, r *rand.Rand) error
+	}{
+		{"init/complete", checkInitComplete},
+		{"termination", checkTermination},
+		{"isolation", checkIsolation},
+		{"processors", checkProcessors},
+	}
+
+	failed := make(map[string]bool)
+	for _, ch := range checks {
+		if ch.name == "processors" && failed["init/complete"] {
+			// the Go processor can't cope with being completed twice
+			continue
+		}
+
+		for i := 0; i < c.Runs; i++ {
+			input := c.Gen(r)
+			if err := ch.check(td, input, r); err != nil {
+				// one failure per law is plenty
+				t.Errorf("%s: %v\n\tinput: %v\n\tseed: %d", ch.name, err, input, c.Seed)
+				failed[ch.name] = true
+				break
+			}
+		}
+	}
+}
+
+func randomInts(r *rand.Rand) []interface{} {
+	input := make([]interface{}, r.Intn(51))
+	for k := range input {
+		input[k] = r.Intn(20)
+	}
+	return input
+}
+
+// Turns any ValueStreams in a value into slices, so they can be compared.
+func normalize(value interface{}) interface{} {
+	if vs, ok := value.(transducers.ValueStream); ok {
+		return transducers.ToSlice(vs)
+	}
+	return value
+}
+
+// A bottom reducer that records everything that happens to it. If stopAfter is
+// more than zero, it terminates after that many steps.
+type probe struct {
+	stopAfter  int
+	out        []interface{}
+	inits      int
+	completes  int
+	terminated bool
+	late       bool // stepped after terminating
+}
+
+func (p *probe) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	if p.terminated {
+		p.late = true
+		return accum, true
+	}
+
+	p.out = append(p.out, normalize(value))
+	if p.stopAfter > 0 && len(p.out) >= p.stopAfter {
+		p.terminated = true
+	}
+	return accum, p.terminated
+}
+
+func (p *probe) Complete(accum interface{}) interface{} {
+	p.completes++
+	return accum
+}
+
+func (p *probe) Init() interface{} {
+	p.inits++
+	return nil
+}
+
+// Sits on top of the transducer being checked, to see whether it passes
+// termination back up.
+type guard struct {
+	next       transducers.Reducer
+	p          *probe
+	swallowed  bool
+	terminated bool
+}
+
+func (g *guard) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	wasTerminated := g.p.terminated
+	accum, g.terminated = g.next.Step(accum, value)
+	if g.p.terminated && !wasTerminated && !g.terminated {
+		g.swallowed = true
+	}
+	return accum, g.terminated
+}
+
+func (g *guard) Complete(accum interface{}) interface{} {
+	return g.next.Complete(accum)
+}
+
+func (g *guard) Init() interface{} {
+	return g.next.Init()
+}
+
+// Runs the input through the transducer with Transduce, into a probe.
+func transduce(td transducers.Transducer, input []interface{}, stopAfter int) (*probe, *guard) {
+	p := &probe{stopAfter: stopAfter}
+	var g *guard
+	top := func(r transducers.Reducer) transducers.Reducer {
+		g = &guard{next: r, p: p}
+		return g
+	}
+
+	transducers.Transduce(transducers.ToStream(input), p, top, td)
+	return p, g
+}
+
+func checkInitComplete(td transducers.Transducer, input []interface{}, r *rand.Rand) error {
+	p, _ := transduce(td, input, 0)
+	if p.inits != 1 {
+		return fmt.Errorf("Init was passed along %v times", p.inits)
+	}
+	if p.completes != 1 {
+		return fmt.Errorf("Complete was passed along %v times", p.completes)
+	}
+	return nil
+}
+
+func checkTermination(td transducers.Transducer, input []interface{}, r *rand.Rand) error {
+	p, g := transduce(td, input, 1+r.Intn(5))
+	if p.late {
+		return fmt.Errorf("stepped the next reducer after it terminated")
+	}
+	if g.swallowed {
+		return fmt.Errorf("the next reducer terminated, but the transducer didn't")
+	}
+	if p.completes != 1 {
+		return fmt.Errorf("Complete was passed along %v times after terminating", p.completes)
+	}
+	return nil
+}
+
+func checkIsolation(td transducers.Transducer, input []interface{}, r *rand.Rand) error {
+	first, _ := transduce(td, input, 0)
+	second, _ := transduce(td, input, 0)
+	if !same(first.out, second.out) {
+		return fmt.Errorf("a second pipeline gave %v, but the first gave %v", second.out, first.out)
+	}
+
+	// now two at once, stepped in lockstep
+	pa, pb := &probe{}, &probe{}
+	a, b := transducers.CreatePipeline(pa, td), transducers.CreatePipeline(pb, td)
+	accA, accB := a.Init(), b.Init()
+	var termA, termB bool
+	for _, v := range input {
+		if !termA {
+			accA, termA = a.Step(accA, v)
+		}
+		if !termB {
+			accB, termB = b.Step(accB, v)
+		}
+	}
+	a.Complete(accA)
+	b.Complete(accB)
+
+	if !same(first.out, pa.out) || !same(first.out, pb.out) {
+		return fmt.Errorf("pipelines run at the same time gave %v and %v, but one alone gave %v", pa.out, pb.out, first.out)
+	}
+	return nil
+}
+
+func checkProcessors(td transducers.Transducer, input []interface{}, r *rand.Rand) error {
+	p, _ := transduce(td, input, 0)
+
+	eduction := transducers.ToSlice(transducers.Eduction(transducers.ToStream(input), td))
+	if !same(p.out, eduction) {
+		return fmt.Errorf("Eduction gave %v, but Transduce gave %v", eduction, p.out)
+	}
+
+	var gone []interface{}
+	for v := range transducers.Go(transducers.ToStream(input), 0, td) {
+		gone = append(gone, normalize(v))
+	}
+	if !same(p.out, gone) {
+		return fmt.Errorf("Go gave %v, but Transduce gave %v", gone, p.out)
+	}
+
+	return nil
+}
+
+func same(a, b []interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,109 @@
+package transducerstest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/sdboyer/transducers-go"
+)
+
+// Stands in for a *testing.T, collecting errors rather than failing.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Helper() {}
+
+func TestShipped(t *testing.T) {
+	for name, td := range map[string]transducers.Transducer{
+		"Map":          transducers.Map(transducers.Inc),
+		"Filter":       transducers.Filter(transducers.Even),
+		"Mapcat":       transducers.Mapcat(transducers.Range),
+		"Dedupe":       transducers.Dedupe(),
+		"Distinct":     transducers.Distinct(),
+		"Chunk":        transducers.Chunk(3),
+		"PartitionAll": transducers.PartitionAll(3, 2),
+		"Take":         transducers.Take(4),
+		"TakeNth":      transducers.TakeNth(3),
+		"RandomSampleSource": transducers.RandomSampleSource(0.5, func() rand.Source {
+			return rand.NewSource(7)
+		}),
+		"Drop": transducers.Drop(2),
+		"Scan": transducers.Scan(0, func(a, v interface{}) (interface{}, bool) { return a.(int) + v.(int), false }),
+		"Comp": transducers.Comp(transducers.Drop(1), transducers.Chunk(2), transducers.Take(3)),
+		"Keyed": transducers.Keyed(func(v interface{}) interface{} { return v.(int) % 2 }, func() transducers.Transducer {
+			return transducers.Comp(transducers.Dedupe(), transducers.Take(3))
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			Check(t, td)
+		})
+	}
+}
+
+// Like TakeNth used to be: the count lives in the closure, so it's shared by
+// every pipeline.
+func leakyTakeNth(n int) transducers.Transducer {
+	var count int
+	return func(r transducers.Reducer) transducers.Reducer {
+		return transducers.CreatePipeline(r, transducers.Filter(func(interface{}) bool {
+			count++
+			return count%n == 0
+		}))
+	}
+}
+
+// Forgets about termination, and flushes on Complete regardless.
+type sloppy struct {
+	next transducers.Reducer
+	held []interface{}
+}
+
+func (s *sloppy) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	s.held = append(s.held, value)
+	if len(s.held) == 2 {
+		accum, _ = s.next.Step(accum, s.held[0])
+		s.held = s.held[1:]
+	}
+	return accum, false
+}
+
+func (s *sloppy) Complete(accum interface{}) interface{} {
+	for _, v := range s.held {
+		accum, _ = s.next.Step(accum, v)
+	}
+	accum = s.next.Complete(accum)
+	return s.next.Complete(accum)
+}
+
+func (s *sloppy) Init() interface{} {
+	return s.next.Init()
+}
+
+func TestCatchesViolations(t *testing.T) {
+	rec := &recorder{TB: t}
+	CheckConfig(rec, leakyTakeNth(3), Config{Seed: 1})
+	if len(rec.errs) == 0 || !strings.HasPrefix(rec.errs[0], "isolation") {
+		t.Error("Expected shared state to be caught, got", rec.errs)
+	}
+
+	rec = &recorder{TB: t}
+	CheckConfig(rec, func(r transducers.Reducer) transducers.Reducer {
+		return &sloppy{next: r}
+	}, Config{Seed: 1})
+
+	var caught []string
+	for _, e := range rec.errs {
+		caught = append(caught, e[:strings.Index(e, ":")])
+	}
+	if strings.Join(caught, ",") != "init/complete,termination" {
+		t.Error("Expected double Complete and ignored termination to be caught, got", rec.errs)
+	}
+}
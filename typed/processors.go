@@ -0,0 +1,97 @@
+package typed
+
+// Transduce performs a non-lazy traversal/reduction over the provided stream.
+func Transduce[Acc, A, B any](src Stream[A], bottom Reducer[Acc, B], xf Transducer[A, B]) Acc {
+	t := CreatePipeline(bottom, xf)
+
+	ret := t.Init()
+	var terminate bool
+
+	for v, done := src(); !done; v, done = src() {
+		ret, terminate = t.Step(ret, v)
+		if terminate {
+			break
+		}
+	}
+
+	return t.Complete(ret)
+}
+
+// Applies the transducer to the provided stream lazily, returning a stream
+// that pulls values through the pipeline on demand.
+//
+// Works just like the untyped Eduction: inputs are fed in until at least one
+// value emerges, and extras are queued up for subsequent calls.
+func Eduction[A, B any](src Stream[A], xf Transducer[A, B]) Stream[B] {
+	pipe := CreatePipeline(Append[B](), xf)
+	var queue []B
+	var finished bool
+
+	return func() (value B, done bool) {
+		for {
+			if len(queue) > 0 {
+				value, queue = queue[0], queue[1:]
+				return value, false
+			}
+
+			if finished {
+				return value, true
+			}
+
+			input, exhausted := src()
+			if exhausted {
+				finished = true
+				queue = pipe.Complete(queue)
+				continue
+			}
+
+			var terminate bool
+			queue, terminate = pipe.Step(queue, input)
+			if terminate {
+				finished = true
+				queue = pipe.Complete(queue)
+			}
+		}
+	}
+}
+
+// Given a channel, apply the transducer to values it produces, emitting values
+// out the other end through the returned channel.
+//
+// As with the untyped Go, this spawns a goroutine that runs the transduction;
+// retcap is the buffer size of the returned channel.
+func Go[A, B any](c <-chan A, retcap int, xf Transducer[A, B]) <-chan B {
+	out := make(chan B, retcap)
+	pipe := CreatePipeline[struct{}](chanReducer[B]{out}, xf)
+
+	go func() {
+		var accum struct{} // accum is unused in this mode
+		for v := range c {
+			if _, terminate := pipe.Step(accum, v); terminate {
+				break
+			}
+		}
+
+		pipe.Complete(accum)
+	}()
+
+	return out
+}
+
+type chanReducer[T any] struct {
+	c chan<- T
+}
+
+func (c chanReducer[T]) Step(accum struct{}, value T) (struct{}, bool) {
+	c.c <- value
+	return accum, false
+}
+
+func (c chanReducer[T]) Complete(accum struct{}) struct{} {
+	close(c.c)
+	return accum
+}
+
+func (c chanReducer[T]) Init() struct{} {
+	return struct{}{}
+}
@@ -0,0 +1,90 @@
+package typed
+
+import (
+	"iter"
+
+	"github.com/sdboyer/transducers-go"
+)
+
+// A Stream is the typed counterpart to transducers.ValueStream: call it, and
+// if the second value is true, it's exhausted. If not, the first is the next
+// value.
+type Stream[T any] func() (value T, done bool)
+
+// Convenience function that receives from the stream and passes each value to
+// an injected function, until the stream reports being exhausted.
+func (s Stream[T]) Each(f func(T)) {
+	for {
+		v, done := s()
+		if done {
+			return
+		}
+		f(v)
+	}
+}
+
+// Seq adapts the stream to a standard library iterator, so it can be used
+// directly in a range loop.
+func (s Stream[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v, done := s(); !done; v, done = s() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// AsStream makes Streams Streamable, so they can be handed directly to the
+// untyped processors.
+func (s Stream[T]) AsStream() transducers.ValueStream {
+	return func() (interface{}, bool) {
+		v, done := s()
+		if done {
+			return nil, true
+		}
+		return v, false
+	}
+}
+
+// FromSlice creates a stream over the values in a slice.
+func FromSlice[T any](slice []T) Stream[T] {
+	var pos int
+	return func() (value T, done bool) {
+		if pos >= len(slice) {
+			return value, true
+		}
+		value = slice[pos]
+		pos++
+		return value, false
+	}
+}
+
+// FromChan creates a stream that receives from a channel until it's closed.
+func FromChan[T any](c <-chan T) Stream[T] {
+	return func() (value T, done bool) {
+		value, ok := <-c
+		return value, !ok
+	}
+}
+
+// FromValueStream adapts an untyped stream. Each value is asserted to T, and
+// panics if it isn't one.
+func FromValueStream[T any](vs transducers.ValueStream) Stream[T] {
+	return func() (value T, done bool) {
+		v, done := vs()
+		if done {
+			return value, true
+		}
+		return v.(T), false
+	}
+}
+
+// Reads the stream out into a slice. Unsafe for infinite streams, and will
+// block if the stream is based on a blocking datasource (e.g., chan).
+func ToSlice[T any](s Stream[T]) (into []T) {
+	s.Each(func(v T) {
+		into = append(into, v)
+	})
+	return into
+}
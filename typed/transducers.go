@@ -0,0 +1,365 @@
+package typed
+
+// Most typed transducers need to pass Complete and Init through untouched.
+type reducerBase[T any] struct {
+	next Reducer[any, T]
+}
+
+func (r reducerBase[T]) Complete(accum any) any {
+	return r.next.Complete(accum)
+}
+
+func (r reducerBase[T]) Init() any {
+	return r.next.Init()
+}
+
+type mapR[A, B any] struct {
+	reducerBase[B]
+	f func(A) B
+}
+
+func (r mapR[A, B]) Step(accum any, value A) (any, bool) {
+	return r.next.Step(accum, r.f(value))
+}
+
+// Map calls its predicate once for each value coming through, passing the
+// result along to the next step.
+func Map[A, B any](f func(A) B) Transducer[A, B] {
+	return func(r Reducer[any, B]) Reducer[any, A] {
+		return mapR[A, B]{reducerBase[B]{r}, f}
+	}
+}
+
+type filter[T any] struct {
+	reducerBase[T]
+	f func(T) bool
+}
+
+func (r filter[T]) Step(accum any, value T) (any, bool) {
+	if r.f(value) {
+		return r.next.Step(accum, value)
+	}
+	return accum, false
+}
+
+// Filter drops values for which its predicate returns false.
+func Filter[T any](f func(T) bool) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return filter[T]{reducerBase[T]{r}, f}
+	}
+}
+
+// Remove drops values for which its predicate returns true. It is the inverse
+// of Filter.
+func Remove[T any](f func(T) bool) Transducer[T, T] {
+	return Filter(func(value T) bool {
+		return !f(value)
+	})
+}
+
+type mapcat[A, B any] struct {
+	reducerBase[B]
+	f func(A) Stream[B]
+}
+
+func (r mapcat[A, B]) Step(accum any, value A) (any, bool) {
+	stream := r.f(value)
+
+	var terminate bool
+	for v, done := stream(); !done; v, done = stream() {
+		accum, terminate = r.next.Step(accum, v)
+		if terminate {
+			break
+		}
+	}
+
+	return accum, terminate
+}
+
+// Mapcat first runs an exploder, then 'concats' results by passing each
+// individual value along to the next transducer in the pipeline.
+func Mapcat[A, B any](f func(A) Stream[B]) Transducer[A, B] {
+	return func(r Reducer[any, B]) Reducer[any, A] {
+		return mapcat[A, B]{reducerBase[B]{r}, f}
+	}
+}
+
+type keep[A, B any] struct {
+	reducerBase[B]
+	f func(A) (B, bool)
+}
+
+func (r keep[A, B]) Step(accum any, value A) (any, bool) {
+	if nv, ok := r.f(value); ok {
+		return r.next.Step(accum, nv)
+	}
+	return accum, false
+}
+
+// Keep calls the provided function, and passes along its result only if it
+// also returns true. Without nil to lean on, that bool is how a typed Keep
+// knows to discard.
+func Keep[A, B any](f func(A) (B, bool)) Transducer[A, B] {
+	return func(r Reducer[any, B]) Reducer[any, A] {
+		return keep[A, B]{reducerBase[B]{r}, f}
+	}
+}
+
+type keepIndexed[A, B any] struct {
+	reducerBase[B]
+	count int
+	f     func(int, A) (B, bool)
+}
+
+func (r *keepIndexed[A, B]) Step(accum any, value A) (any, bool) {
+	nv, ok := r.f(r.count, value)
+	r.count++
+
+	if ok {
+		return r.next.Step(accum, nv)
+	}
+	return accum, false
+}
+
+// KeepIndexed is Keep, but also passes the number of values seen so far.
+func KeepIndexed[A, B any](f func(int, A) (B, bool)) Transducer[A, B] {
+	return func(r Reducer[any, B]) Reducer[any, A] {
+		return &keepIndexed[A, B]{reducerBase[B]{r}, 0, f}
+	}
+}
+
+// Replace swaps any value that has a key in the map for the corresponding value.
+func Replace[T comparable](pairs map[T]T) Transducer[T, T] {
+	return Map(func(value T) T {
+		if v, exists := pairs[value]; exists {
+			return v
+		}
+		return value
+	})
+}
+
+type dedupe[T comparable] struct {
+	reducerBase[T]
+	last *T
+}
+
+func (r *dedupe[T]) Step(accum any, value T) (any, bool) {
+	if r.last != nil && *r.last == value {
+		return accum, false
+	}
+
+	r.last = &value
+	return r.next.Step(accum, value)
+}
+
+// Dedupe drops any value that's the same as the one just before it. To drop
+// every repeat, use Distinct.
+func Dedupe[T comparable]() Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return &dedupe[T]{reducerBase: reducerBase[T]{r}}
+	}
+}
+
+type distinct[T comparable] struct {
+	reducerBase[T]
+	seen map[T]struct{}
+}
+
+func (r distinct[T]) Step(accum any, value T) (any, bool) {
+	if _, seen := r.seen[value]; seen {
+		return accum, false
+	}
+
+	r.seen[value] = struct{}{}
+	return r.next.Step(accum, value)
+}
+
+// Distinct keeps track of values that have passed through it during this
+// transduction process and drops any repeats.
+func Distinct[T comparable]() Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return distinct[T]{reducerBase[T]{r}, make(map[T]struct{})}
+	}
+}
+
+type takeNth[T any] struct {
+	reducerBase[T]
+	n, count int
+}
+
+func (r *takeNth[T]) Step(accum any, value T) (any, bool) {
+	r.count++
+	if r.count%r.n == 0 {
+		return r.next.Step(accum, value)
+	}
+	return accum, false
+}
+
+// TakeNth takes every nth element to pass through it, discarding the remainder.
+func TakeNth[T any](n int) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return &takeNth[T]{reducerBase[T]{r}, n, 0}
+	}
+}
+
+type take[T any] struct {
+	reducerBase[T]
+	max, count uint
+}
+
+func (r *take[T]) Step(accum any, value T) (any, bool) {
+	if r.count >= r.max {
+		return accum, true
+	}
+
+	r.count++
+	accum, terminate := r.next.Step(accum, value)
+	return accum, terminate || r.count >= r.max
+}
+
+// Take specifies a maximum number of values to receive, after which it will
+// terminate the transducing process.
+func Take[T any](max uint) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return &take[T]{reducerBase[T]{r}, max, 0}
+	}
+}
+
+type takeWhile[T any] struct {
+	reducerBase[T]
+	f func(T) bool
+}
+
+func (r takeWhile[T]) Step(accum any, value T) (any, bool) {
+	if !r.f(value) {
+		return accum, true
+	}
+	return r.next.Step(accum, value)
+}
+
+// TakeWhile accepts values until the injected predicate returns false.
+func TakeWhile[T any](f func(T) bool) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return takeWhile[T]{reducerBase[T]{r}, f}
+	}
+}
+
+type drop[T any] struct {
+	reducerBase[T]
+	min, count uint
+}
+
+func (r *drop[T]) Step(accum any, value T) (any, bool) {
+	if r.count < r.min {
+		r.count++
+		return accum, false
+	}
+	return r.next.Step(accum, value)
+}
+
+// Drop specifies a number of values to initially ignore, after which it will
+// let everything through unchanged.
+func Drop[T any](min uint) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return &drop[T]{reducerBase[T]{r}, min, 0}
+	}
+}
+
+type dropWhile[T any] struct {
+	reducerBase[T]
+	f        func(T) bool
+	accepted bool
+}
+
+func (r *dropWhile[T]) Step(accum any, value T) (any, bool) {
+	if !r.accepted {
+		if r.f(value) {
+			return accum, false
+		}
+		r.accepted = true
+	}
+	return r.next.Step(accum, value)
+}
+
+// DropWhile drops values until the injected predicate returns false.
+func DropWhile[T any](f func(T) bool) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return &dropWhile[T]{reducerBase[T]{r}, f, false}
+	}
+}
+
+type chunk[T any] struct {
+	reducerBase[[]T]
+	length    int
+	coll      []T
+	terminate bool
+}
+
+func (r *chunk[T]) Step(accum any, value T) (any, bool) {
+	r.coll = append(r.coll, value)
+	if len(r.coll) == r.length {
+		// hand off the full chunk, and start a fresh one - never reuse the
+		// backing array, as downstream may hold on to what it was given
+		full := r.coll
+		r.coll = make([]T, 0, r.length)
+		accum, r.terminate = r.next.Step(accum, full)
+	}
+	return accum, r.terminate
+}
+
+func (r *chunk[T]) Complete(accum any) any {
+	// if there's a partially-completed chunk, send it through reduction as-is
+	if len(r.coll) != 0 && !r.terminate {
+		accum, r.terminate = r.next.Step(accum, r.coll)
+	}
+	return r.next.Complete(accum)
+}
+
+// Chunk partitions the values passing through into slices of the given
+// length. Any leftover partial chunk is sent along on Complete.
+func Chunk[T any](length int) Transducer[T, []T] {
+	if length < 1 {
+		panic("chunks must be at least one element in size")
+	}
+
+	return func(r Reducer[any, []T]) Reducer[any, T] {
+		return &chunk[T]{reducerBase: reducerBase[[]T]{r}, length: length, coll: make([]T, 0, length)}
+	}
+}
+
+type chunkBy[T any, K comparable] struct {
+	reducerBase[[]T]
+	f         func(T) K
+	last      K
+	coll      []T
+	terminate bool
+}
+
+func (r *chunkBy[T, K]) Step(accum any, value T) (any, bool) {
+	key := r.f(value)
+	if len(r.coll) != 0 && key != r.last {
+		full := r.coll
+		r.coll = nil
+		accum, r.terminate = r.next.Step(accum, full)
+	}
+
+	r.last = key
+	r.coll = append(r.coll, value)
+	return accum, r.terminate
+}
+
+func (r *chunkBy[T, K]) Complete(accum any) any {
+	if len(r.coll) != 0 && !r.terminate {
+		accum, r.terminate = r.next.Step(accum, r.coll)
+	}
+	return r.next.Complete(accum)
+}
+
+// ChunkBy partitions the values passing through into slices, starting a new
+// one every time the injected function returns a different value from the
+// previous.
+func ChunkBy[T any, K comparable](f func(T) K) Transducer[T, []T] {
+	return func(r Reducer[any, []T]) Reducer[any, T] {
+		return &chunkBy[T, K]{reducerBase: reducerBase[[]T]{r}, f: f}
+	}
+}
@@ -0,0 +1,254 @@
+// Package typed is a type-parameterized layer over transducers.
+//
+// The root package traffics entirely in interface{}, which means every
+// predicate starts with a type assertion, and a wrong one panics at runtime.
+// Here, Transducers and Reducers carry their element types, so the compiler
+// catches a Map(strconv.Itoa) sitting on top of a Filter that wants strings.
+//
+// The two worlds interoperate through explicit adapters: Lift brings an
+// untyped Transducer into a typed stack, and Erase sends a typed Transducer the
+// other way, so existing stacks keep working. The type assertions don't go
+// away at those seams - they just happen in exactly one place.
+package typed
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sdboyer/transducers-go"
+)
+
+// A Reducer is the typed counterpart to transducers.Reducer: a reducing step
+// over values of type T, building up an accumulator of type Acc.
+type Reducer[Acc, T any] interface {
+	// The primary reducing step function, called during normal operation.
+	Step(accum Acc, value T) (result Acc, terminate bool)
+
+	// Complete is called when the input has been exhausted; stateful transducers
+	// should flush any held state through here.
+	Complete(accum Acc) (result Acc)
+
+	// Certain processors will call this to get an initial value for the accumulator.
+	Init() Acc
+}
+
+// A Transducer transforms a reducing function over Bs into a reducing function
+// over As - that is, it sits in a pipeline receiving As, and sends Bs along.
+//
+// The accumulator is deliberately left untyped at this level. Go has no way to
+// express a function that's generic over the accumulator type, and fixing it
+// would mean a stack could only ever be reused with one kind of bottom
+// reducer - which defeats the purpose. Processors restore the accumulator's
+// type at the bottom, where it's actually produced.
+type Transducer[A, B any] func(Reducer[any, B]) Reducer[any, A]
+
+// Compose joins two transducers into one; values pass through f, then g.
+//
+// Since stacks no longer share a single type, they can't be passed around as a
+// slice. Nest calls to Compose instead - or, for stacks where every stage
+// keeps the same type, use Stack.
+func Compose[A, B, C any](f Transducer[A, B], g Transducer[B, C]) Transducer[A, C] {
+	return func(r Reducer[any, C]) Reducer[any, A] {
+		return f(g(r))
+	}
+}
+
+// Stack composes any number of same-typed transducers, in order.
+func Stack[T any](tds ...Transducer[T, T]) Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		// walk backwards from the bottom, same as transducers.CreatePipeline
+		for i := len(tds) - 1; i >= 0; i-- {
+			r = tds[i](r)
+		}
+		return r
+	}
+}
+
+// Identity passes every value along unchanged.
+func Identity[T any]() Transducer[T, T] {
+	return func(r Reducer[any, T]) Reducer[any, T] {
+		return r
+	}
+}
+
+// Creates a transduction pipeline from a bottom reducer and a transducer.
+//
+// As with the untyped version, creating the pipeline initializes state in the
+// transducers, so each process needs a fresh one. Processors call this for you.
+func CreatePipeline[Acc, A, B any](bottom Reducer[Acc, B], xf Transducer[A, B]) Reducer[Acc, A] {
+	return restored[Acc, A]{xf(erased[Acc, B]{bottom})}
+}
+
+// erased hides the bottom reducer's accumulator type from the transducers.
+type erased[Acc, T any] struct {
+	r Reducer[Acc, T]
+}
+
+func (e erased[Acc, T]) Step(accum any, value T) (any, bool) {
+	return e.r.Step(as[Acc](accum), value)
+}
+
+func (e erased[Acc, T]) Complete(accum any) any {
+	return e.r.Complete(as[Acc](accum))
+}
+
+func (e erased[Acc, T]) Init() any {
+	return e.r.Init()
+}
+
+// restored puts the accumulator type back on the top of a pipeline. The only
+// values that ever come out of it were produced by the erased bottom, so the
+// assertion is safe.
+type restored[Acc, T any] struct {
+	r Reducer[any, T]
+}
+
+func (p restored[Acc, T]) Step(accum Acc, value T) (Acc, bool) {
+	ret, terminate := p.r.Step(accum, value)
+	return as[Acc](ret), terminate
+}
+
+func (p restored[Acc, T]) Complete(accum Acc) Acc {
+	return as[Acc](p.r.Complete(accum))
+}
+
+func (p restored[Acc, T]) Init() Acc {
+	return as[Acc](p.r.Init())
+}
+
+// as asserts to T, mapping nil to T's zero value rather than panicking (which
+// a plain assertion would do when T is itself an interface type). Anything
+// else that isn't a T still panics.
+func as[T any](v any) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return v.(T)
+}
+
+// ReduceStep is a bare typed reducing function. It implements Reducer, with
+// Complete passing the accumulator through and Init returning the zero Acc.
+type ReduceStep[Acc, T any] func(accum Acc, value T) (result Acc, terminate bool)
+
+func (r ReduceStep[Acc, T]) Step(accum Acc, value T) (Acc, bool) {
+	return r(accum, value)
+}
+
+func (r ReduceStep[Acc, T]) Complete(accum Acc) Acc {
+	return accum
+}
+
+func (r ReduceStep[Acc, T]) Init() Acc {
+	var zero Acc
+	return zero
+}
+
+// Append is a bottom reducer that collects values into a []T.
+func Append[T any]() Reducer[[]T, T] {
+	return ReduceStep[[]T, T](func(accum []T, value T) ([]T, bool) {
+		return append(accum, value), false
+	})
+}
+
+/* Adapters to and from the untyped world */
+
+// typedReducer presents an untyped Reducer as a typed one. Values going in
+// are already the right type, so nothing needs asserting.
+type typedReducer[T any] struct {
+	r transducers.Reducer
+}
+
+func (r typedReducer[T]) Step(accum any, value T) (any, bool) {
+	return r.r.Step(accum, value)
+}
+
+func (r typedReducer[T]) Complete(accum any) any {
+	return r.r.Complete(accum)
+}
+
+func (r typedReducer[T]) Init() any {
+	return r.r.Init()
+}
+
+// untypedReducer presents a typed Reducer as an untyped one. This is the
+// seam: values arriving from untyped stages are asserted to T, and a value of
+// the wrong type panics, exactly as it would have in the untyped stage.
+type untypedReducer[T any] struct {
+	r Reducer[any, T]
+}
+
+func (r untypedReducer[T]) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	return r.r.Step(accum, value.(T))
+}
+
+func (r untypedReducer[T]) Complete(accum interface{}) interface{} {
+	return r.r.Complete(accum)
+}
+
+func (r untypedReducer[T]) Init() interface{} {
+	return r.r.Init()
+}
+
+// Lift adapts an untyped transducer for use in a typed stack. The caller
+// asserts, via A and B, what the untyped transducer receives and emits.
+//
+// If it ever emits something that isn't a B, that panics.
+func Lift[A, B any](td transducers.Transducer) Transducer[A, B] {
+	return func(r Reducer[any, B]) Reducer[any, A] {
+		return typedReducer[A]{td(untypedReducer[B]{r})}
+	}
+}
+
+// Erase adapts a typed transducer for use in an untyped stack.
+//
+// If the untyped stage above it sends along something that isn't an A, that
+// panics.
+func Erase[A, B any](xf Transducer[A, B]) transducers.Transducer {
+	return func(r transducers.Reducer) transducers.Reducer {
+		return untypedReducer[A]{xf(typedReducer[B]{r})}
+	}
+}
+
+// FromReducer adapts an untyped bottom reducer for use with typed processors.
+// Accumulators coming out of it are asserted to Acc; if one isn't an Acc (or
+// nil), that panics, naming both types.
+func FromReducer[Acc, T any](r transducers.Reducer) Reducer[Acc, T] {
+	return fromReducer[Acc, T]{r}
+}
+
+type fromReducer[Acc, T any] struct {
+	r transducers.Reducer
+}
+
+func (f fromReducer[Acc, T]) Step(accum Acc, value T) (Acc, bool) {
+	ret, terminate := f.r.Step(accum, value)
+	return accOf[Acc](ret), terminate
+}
+
+func (f fromReducer[Acc, T]) Complete(accum Acc) Acc {
+	return accOf[Acc](f.r.Complete(accum))
+}
+
+func (f fromReducer[Acc, T]) Init() Acc {
+	return accOf[Acc](f.r.Init())
+}
+
+// Unlike inside a pipeline, the accumulators from an untyped reducer can be
+// anything, so a mismatch gets a proper explanation.
+func accOf[Acc any](v any) Acc {
+	if v == nil {
+		var zero Acc
+		return zero
+	}
+	acc, ok := v.(Acc)
+	if !ok {
+		panic(fmt.Sprintf("typed.FromReducer: accumulator is %T, not %v", v, reflect.TypeFor[Acc]()))
+	}
+	return acc
+}
+
+// ToReducer adapts a typed bottom reducer for use with untyped processors.
+func ToReducer[Acc, T any](r Reducer[Acc, T]) transducers.Reducer {
+	return untypedReducer[T]{erased[Acc, T]{r}}
+}
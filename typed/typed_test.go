@@ -0,0 +1,175 @@
+package typed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sdboyer/transducers-go"
+)
+
+func inc(i int) int {
+	return i + 1
+}
+
+func even(i int) bool {
+	return i%2 == 0
+}
+
+func rng(n int) Stream[int] {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return FromSlice(s)
+}
+
+func sliceEquals[T comparable](expected, actual []T, t *testing.T) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+		return
+	}
+
+	for k, v := range expected {
+		if actual[k] != v {
+			t.Errorf("Error on index %v: expected %v got %v", k, v, actual[k])
+		}
+	}
+}
+
+func TestTransduceMapFilter(t *testing.T) {
+	mf := Transduce(rng(5), Append[int](), Compose(Map(inc), Filter(even)))
+	sliceEquals([]int{2, 4}, mf, t)
+
+	fm := Transduce(rng(5), Append[int](), Compose(Filter(even), Map(inc)))
+	sliceEquals([]int{1, 3, 5}, fm, t)
+}
+
+func TestTransduceChangesType(t *testing.T) {
+	xf := Compose(Compose(Filter(even), Map(strconv.Itoa)), Chunk[string](2))
+	result := Transduce(rng(10), Append[[]string](), xf)
+
+	if fmt.Sprint(result) != "[[0 2] [4 6] [8]]" {
+		t.Errorf("Unexpected result %v", result)
+	}
+}
+
+func TestStack(t *testing.T) {
+	xf := Stack(Drop[int](1), Remove(even), TakeNth[int](2), Take[int](3))
+
+	result := Transduce(rng(20), Append[int](), xf)
+	sliceEquals([]int{3, 7, 11}, result, t)
+
+	// stateful stages must not share state across pipelines
+	result2 := Transduce(rng(20), Append[int](), xf)
+	sliceEquals([]int{3, 7, 11}, result2, t)
+}
+
+func TestTakeZero(t *testing.T) {
+	result := Transduce(rng(5), Append[int](), Take[int](0))
+	sliceEquals([]int{}, result, t)
+}
+
+func TestMapcatDistinctKeep(t *testing.T) {
+	xf := Compose(Compose(Mapcat(rng), Distinct[int]()), Keep(func(i int) (string, bool) {
+		return strconv.Itoa(i * i), i%2 != 0
+	}))
+
+	result := Transduce(FromSlice([]int{3, 5, 2}), Append[string](), xf)
+	sliceEquals([]string{"1", "9"}, result, t)
+}
+
+func TestDedupe(t *testing.T) {
+	result := Transduce(FromSlice([]int{1, 1, 2, 2, 1, 3}), Append[int](), Dedupe[int]())
+	sliceEquals([]int{1, 2, 1, 3}, result, t)
+}
+
+func TestChunkBy(t *testing.T) {
+	xf := ChunkBy(func(i int) bool {
+		return i > 3 && i < 7
+	})
+
+	result := Transduce(rng(10), Append[[]int](), xf)
+	if fmt.Sprint(result) != "[[0 1 2 3] [4 5 6] [7 8 9]]" {
+		t.Errorf("Unexpected result %v", result)
+	}
+}
+
+func TestEduction(t *testing.T) {
+	sliceEquals([]int{1, 2, 3, 4, 5}, ToSlice(Eduction(rng(5), Map(inc))), t)
+
+	xf := Compose(Compose(Map(inc), Mapcat(rng)), Take[int](5))
+	sliceEquals([]int{0, 0, 1, 0, 1}, ToSlice(Eduction(rng(5), xf)), t)
+
+	chunked := Eduction(rng(5), Chunk[int](2))
+	if fmt.Sprint(ToSlice(chunked)) != "[[0 1] [2 3] [4]]" {
+		t.Error("Eduction did not flush the final chunk")
+	}
+}
+
+func TestGo(t *testing.T) {
+	in := make(chan int)
+	go func() {
+		rng(10).Each(func(i int) {
+			in <- i
+		})
+		close(in)
+	}()
+
+	var result []string
+	for v := range Go(in, 0, Compose(Filter(even), Map(strconv.Itoa))) {
+		result = append(result, v)
+	}
+	sliceEquals([]string{"0", "2", "4", "6", "8"}, result, t)
+}
+
+func TestLiftErase(t *testing.T) {
+	// an untyped stage in the middle of a typed stack
+	sum := func(vs transducers.ValueStream) int {
+		return transducers.Sum(vs).(int)
+	}
+	xf := Compose(Compose(Map(inc), Lift[int, transducers.ValueStream](transducers.Chunk(2))), Map(sum))
+	result := Transduce(rng(5), Append[int](), xf)
+	sliceEquals([]int{3, 7, 5}, result, t)
+
+	// a typed stage in the middle of an untyped stack
+	result2 := transducers.Transduce(transducers.Range(6), transducers.Append(),
+		transducers.Filter(transducers.Even),
+		Erase(Map(func(i int) int { return i * 10 })),
+	)
+	sliceEquals([]int{0, 20, 40}, result2.([]int), t)
+}
+
+func TestReducerAdapters(t *testing.T) {
+	// untyped bottom under a typed processor
+	result := Transduce(rng(4), FromReducer[[]int, int](transducers.Append()), Map(inc))
+	sliceEquals([]int{1, 2, 3, 4}, result, t)
+
+	// typed bottom under an untyped processor
+	result2 := transducers.Transduce(rng(4), ToReducer(Append[string]()),
+		Erase(Map(strconv.Itoa)),
+	)
+	sliceEquals([]string{"0", "1", "2", "3"}, result2.([]string), t)
+}
+
+func TestFromReducerMismatch(t *testing.T) {
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "FromReducer") || !strings.Contains(msg, "[]interface {}") || !strings.Contains(msg, "[]int") {
+			t.Error("Expected a panic naming FromReducer and both types, got", msg)
+		}
+	}()
+
+	// CreateStep's Init makes a []interface{}, not a []int
+	Transduce(rng(4), FromReducer[[]int, int](transducers.CreateStep(nil)), Map(inc))
+}
+
+func TestStreamSeq(t *testing.T) {
+	var result []int
+	for v := range Eduction(rng(10), Filter(even)).Seq() {
+		result = append(result, v)
+	}
+	sliceEquals([]int{0, 2, 4, 6, 8}, result, t)
+}
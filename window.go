@@ -0,0 +1,145 @@
+package transducers
+
+import (
+	"sync"
+	"time"
+)
+
+type timeWindow struct {
+	next   Reducer
+	clock  Clock
+	d      time.Duration
+	max    int // no limit if zero
+	mu     sync.Mutex
+	window valueSlice
+	// the window is due to be cut at this time
+	deadline time.Time
+	timer    Timer
+	// counts windows, so that a timer that fires late can tell it's stale
+	gen int
+	// the Go processor's accumulator, if that's what's running the pipeline
+	detached  *detached
+	done      bool
+	terminate bool
+}
+
+func (t *timeWindow) Step(accum interface{}, value interface{}) (interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.terminate {
+		// a timer flushed the last window, and the pipeline terminated
+		return accum, true
+	}
+	if d, ok := accum.(detached); ok && t.detached == nil {
+		t.detached = &d
+	}
+
+	if len(t.window) > 0 && !t.clock.Now().Before(t.deadline) {
+		accum = t.flush(accum)
+		if t.terminate {
+			return accum, true
+		}
+	}
+
+	if len(t.window) == 0 {
+		gen := t.gen
+		t.deadline = t.clock.Now().Add(t.d)
+		t.timer = t.clock.AfterFunc(t.d, func() {
+			t.expire(gen)
+		})
+	}
+
+	t.window = append(t.window, value)
+	if t.max > 0 && len(t.window) == t.max {
+		accum = t.flush(accum)
+	}
+
+	return accum, t.terminate
+}
+
+// Sends the current window along. Must be called with the lock held.
+func (t *timeWindow) flush(accum interface{}) interface{} {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.gen++
+
+	w := t.window
+	t.window = nil
+	accum, t.terminate = t.next.Step(accum, w.AsStream())
+	return accum
+}
+
+// Called by the timer for a window. The accumulator belongs to whatever
+// processor is running the pipeline, so the window can only be flushed from
+// here if the processor doesn't use one - which is the case with Go. Otherwise,
+// it has to wait until the next value shows up, or the pipeline completes.
+func (t *timeWindow) expire(gen int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if gen != t.gen || t.detached == nil || t.done || t.terminate || len(t.window) == 0 {
+		return
+	}
+
+	t.flush(*t.detached)
+	if t.terminate {
+		// no more input is needed, but the processor may be stuck waiting for it
+		t.detached.stop()
+	}
+}
+
+func (t *timeWindow) Complete(accum interface{}) interface{} {
+	t.mu.Lock()
+	t.done = true
+	if len(t.window) > 0 && !t.terminate {
+		accum = t.flush(accum)
+	}
+	t.mu.Unlock()
+
+	return t.next.Complete(accum)
+}
+
+func (t *timeWindow) Init() interface{} {
+	return t.next.Init()
+}
+
+func newTimeWindow(n int, d time.Duration, clock Clock) Transducer {
+	if d <= 0 {
+		panic("windows must have a positive duration")
+	}
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return func(r Reducer) Reducer {
+		return &timeWindow{next: r, clock: clock, d: d, max: n}
+	}
+}
+
+// WindowByTime groups values into windows by the time they arrive, passing
+// each window along as a ValueStream. A window opens when a value arrives, and
+// collects values until d has passed; empty windows are never sent.
+//
+// Under the Go processors, a timer sends each window along as soon as it's
+// over, even if no more input has arrived; if that terminates the pipeline, Go
+// stops waiting on the input and closes its output. The other processors own
+// the accumulator, so that can't be done safely - with them, a window is only
+// sent once the next value arrives after it's over, or the input runs out.
+//
+// Time comes from the given Clock; if it's nil, the SystemClock is used.
+func WindowByTime(d time.Duration, clock Clock) Transducer {
+	return newTimeWindow(0, d, clock)
+}
+
+// BatchBySizeOrTime is WindowByTime, but a window is also cut short as soon
+// as it has n values in it - so a batch is sent along when it's full, or when
+// d has passed since its first value arrived, whichever comes first.
+func BatchBySizeOrTime(n int, d time.Duration, clock Clock) Transducer {
+	if n < 1 {
+		panic("batches must be at least one element in size")
+	}
+	return newTimeWindow(n, d, clock)
+}
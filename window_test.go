@@ -0,0 +1,143 @@
+package transducers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Advances the clock as each of the given values goes by.
+func ticker(clock *ManualClock, d time.Duration, at ...int) Transducer {
+	return Map(func(v interface{}) interface{} {
+		for _, a := range at {
+			if v == a {
+				clock.Advance(d)
+			}
+		}
+		return v
+	})
+}
+
+func TestManualClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clock := NewManualClock(start)
+
+	var fired []time.Duration
+	record := func() {
+		fired = append(fired, clock.Now().Sub(start))
+	}
+	clock.AfterFunc(3*time.Second, record)
+	clock.AfterFunc(time.Second, record)
+	stopped := clock.AfterFunc(2*time.Second, record)
+
+	if !stopped.Stop() {
+		t.Error("Stopping a pending timer should report true")
+	}
+	clock.Advance(5 * time.Second)
+
+	if fmt.Sprint(fired) != "[1s 3s]" {
+		t.Error("Timers fired unexpectedly:", fired)
+	}
+	if stopped.Stop() {
+		t.Error("Stopping a stopped timer should report false")
+	}
+}
+
+func TestWindowByTime(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	xf := []Transducer{ticker(clock, time.Minute, 3, 7), WindowByTime(time.Minute, clock)}
+
+	if result := fmt.Sprint(ToSlice(Eduction(Range(10), xf...))); result != "[[0 1 2] [3 4 5 6] [7 8 9]]" {
+		t.Error("Unexpected windows", result)
+	}
+
+	// windows under a minute don't get cut
+	xf = []Transducer{ticker(clock, 30*time.Second, 2, 4, 6), WindowByTime(time.Minute, clock)}
+	if result := fmt.Sprint(ToSlice(Eduction(Range(8), xf...))); result != "[[0 1 2 3] [4 5 6 7]]" {
+		t.Error("Unexpected windows", result)
+	}
+}
+
+func TestBatchBySizeOrTime(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	xf := []Transducer{ticker(clock, time.Minute, 5), BatchBySizeOrTime(3, time.Minute, clock)}
+
+	if result := fmt.Sprint(ToSlice(Eduction(Range(9), xf...))); result != "[[0 1 2] [3 4] [5 6 7] [8]]" {
+		t.Error("Unexpected batches", result)
+	}
+}
+
+func TestWindowByTimeIdleGo(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	idle, stop := make(chan struct{}), make(chan struct{})
+
+	var i int
+	src := ValueStream(func() (interface{}, bool) {
+		if i < 2 {
+			i++
+			return i, false
+		}
+		// everything so far has gone through; now go quiet
+		idle <- struct{}{}
+		<-stop
+		return nil, true
+	})
+
+	out := Go(src, 1, WindowByTime(time.Minute, clock))
+	<-idle
+
+	clock.Advance(time.Minute)
+	if result := fmt.Sprint(ToSlice((<-out).(ValueStream))); result != "[1 2]" {
+		t.Error("Expected the timer to send the window, got", result)
+	}
+
+	close(stop)
+	for v := range out {
+		t.Error("Unexpected value after input finished:", v)
+	}
+}
+
+func TestWindowByTimeTerminatesIdleGo(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	idle, stop := make(chan struct{}), make(chan struct{})
+
+	var i int
+	src := Releasable(func() (interface{}, bool) {
+		if i < 2 {
+			i++
+			return i, false
+		}
+		// go quiet until released
+		idle <- struct{}{}
+		<-stop
+		return nil, true
+	}, func() {
+		close(stop)
+	})
+
+	out, errc := GoContext(context.Background(), src, 1, WindowByTime(time.Minute, clock), Take(1))
+	<-idle
+	clock.Advance(time.Minute)
+
+	select {
+	case v := <-out:
+		if result := fmt.Sprint(ToSlice(v.(ValueStream))); result != "[1 2]" {
+			t.Error("Expected the timer to send the window, got", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timer never sent the window")
+	}
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("Unexpected value after terminating")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Output wasn't closed after the timer's window terminated the pipeline")
+	}
+	if err := <-errc; err != nil {
+		t.Error("Expected no error from terminating, got", err)
+	}
+}
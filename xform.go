@@ -0,0 +1,96 @@
+package transducers
+
+// Comp composes a stack of transducers into a single transducer. Values flow
+// through them in the order they're given, just as they would if the stack
+// were passed directly to a processor.
+//
+// Composed transducers can themselves be composed, and can go anywhere a
+// single transducer can - a processor, AttachLoggers, another Comp, etc. Each
+// pipeline created from one gets its own fresh stack of reducers.
+func Comp(tds ...Transducer) Transducer {
+	stateless := true
+	for _, td := range tds {
+		if !IsStateless(td) {
+			stateless = false
+			break
+		}
+	}
+
+	return func(r Reducer) Reducer {
+		return comp{CreatePipeline(r, tds...), stateless}
+	}
+}
+
+// Wraps the top of a composed pipeline, so it can report whether the whole
+// thing is Stateless, not just its top reducer.
+type comp struct {
+	Reducer
+	stateless bool
+}
+
+func (r comp) Stateless() bool {
+	return r.stateless
+}
+
+// An Xform is a reusable transducer stack, with methods for building it up
+// fluently:
+//
+//	xf := Xform{}.Map(Inc).Filter(Even).Take(5)
+//
+// Like any []Transducer, it can be spread into a processor - Transduce(coll,
+// Append(), xf...) - or it can be turned into a single transducer with
+// Transducer().
+//
+// The methods never modify the stack they're called on, so it's safe to build
+// several different stacks from a common base.
+type Xform []Transducer
+
+// Then returns a new stack, with the given transducers added to the end.
+func (x Xform) Then(tds ...Transducer) Xform {
+	return append(x[:len(x):len(x)], tds...)
+}
+
+// Transducer composes the stack into a single transducer, via Comp.
+func (x Xform) Transducer() Transducer {
+	return Comp(x...)
+}
+
+// Map adds a Map to the end of the stack.
+func (x Xform) Map(f Mapper) Xform {
+	return x.Then(Map(f))
+}
+
+// Filter adds a Filter to the end of the stack.
+func (x Xform) Filter(f Filterer) Xform {
+	return x.Then(Filter(f))
+}
+
+// Remove adds a Remove to the end of the stack.
+func (x Xform) Remove(f Filterer) Xform {
+	return x.Then(Remove(f))
+}
+
+// Mapcat adds a Mapcat to the end of the stack.
+func (x Xform) Mapcat(f Exploder) Xform {
+	return x.Then(Mapcat(f))
+}
+
+// Keep adds a Keep to the end of the stack.
+func (x Xform) Keep(f Mapper) Xform {
+	return x.Then(Keep(f))
+}
+
+// Take adds a Take to the end of the stack.
+func (x Xform) Take(max uint) Xform {
+	return x.Then(Take(max))
+}
+
+// Drop adds a Drop to the end of the stack.
+func (x Xform) Drop(min uint) Xform {
+	return x.Then(Drop(min))
+}
+
+// Chunk adds a Chunk to the end of the stack.
+func (x Xform) Chunk(length int) Xform {
+	return x.Then(Chunk(length))
+}
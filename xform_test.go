@@ -0,0 +1,59 @@
+package transducers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComp(t *testing.T) {
+	inner := Comp(Map(Inc), Filter(Even))
+	xf := Comp(inner, Comp(Take(3)), Map(Inc))
+
+	result := Transduce(Range(20), tb(), xf).([]int)
+	intSliceEquals([]int{3, 5, 7}, result, t)
+
+	// stateful stages get fresh state in each pipeline
+	result = Transduce(Range(20), tb(), xf).([]int)
+	intSliceEquals([]int{3, 5, 7}, result, t)
+
+	streamEquals(toi(3, 5, 7), Eduction(Range(20), xf), t)
+
+	if !IsStateless(inner) {
+		t.Error("A composition of stateless transducers should be stateless")
+	}
+	if IsStateless(xf) {
+		t.Error("A composition containing Take should not be stateless")
+	}
+}
+
+func TestXform(t *testing.T) {
+	base := Xform{}.Map(Inc).Filter(Even)
+	a := base.Take(2)
+	b := base.Then(Drop(1)).Chunk(2)
+
+	intSliceEquals([]int{2, 4}, Transduce(Range(10), tb(), a...).([]int), t)
+	intSliceEquals([]int{2, 4}, Transduce(Range(10), tb(), base.Take(2).Transducer()).([]int), t)
+
+	chunks := ToSlice(Eduction(Range(10), b...))
+	if len(chunks) != 2 || len(chunks[0].([]interface{})) != 2 {
+		t.Error("Unexpected chunks", chunks)
+	}
+
+	if len(base) != 2 {
+		t.Error("Building on an Xform should not modify it")
+	}
+}
+
+func TestCompLoggers(t *testing.T) {
+	var out strings.Builder
+	logger := func(s string, v ...interface{}) (int, error) {
+		out.WriteString(s)
+		return 0, nil
+	}
+
+	xf := AttachLoggers(logger, Comp(Map(Inc), Filter(Even)), Xform{}.Take(2).Transducer())
+	intSliceEquals([]int{2, 4}, Transduce(Range(10), tb(), xf...).([]int), t)
+	if !strings.Contains(out.String(), "END") {
+		t.Error("Loggers did not run around composed transducers")
+	}
+}